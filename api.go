@@ -0,0 +1,185 @@
+package glogger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// apiPost is the JSON representation of a post in the /_api/ endpoints.
+// Field names are part of the API and must not change.
+type apiPost struct {
+	Slug        string   `json:"slug"`
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Tags        []string `json:"tags"`
+	Published   string   `json:"published,omitempty"`
+	Updated     string   `json:"updated,omitempty"`
+	HTML        string   `json:"html,omitempty"`
+	Markdown    string   `json:"markdown,omitempty"`
+}
+
+type apiPostList struct {
+	Posts      []apiPost `json:"posts"`
+	Page       int       `json:"page"`
+	PerPage    int       `json:"per_page"`
+	Total      int       `json:"total"`
+	TotalPages int       `json:"total_pages"`
+}
+
+type apiTag struct {
+	Name  string `json:"name"`
+	Slug  string `json:"slug"`
+	Count int    `json:"count"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+const (
+	apiDefaultPerPage = 20
+	apiMaxPerPage     = 100
+)
+
+func (b *Blog) apiPost(post Post) apiPost {
+	p := apiPost{
+		Slug:        post.Slug,
+		URL:         b.absURL("/" + post.Slug),
+		Title:       post.Title,
+		Description: post.Description,
+		Author:      post.Author,
+		Tags:        post.Tags,
+	}
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+	if !post.PublishDate.IsZero() {
+		p.Published = formatAtomDate(post.PublishDate)
+	}
+	if !post.Updated.IsZero() {
+		p.Updated = formatAtomDate(post.Updated)
+	}
+	return p
+}
+
+// handleAPIPosts lists posts, newest first. Query parameters:
+//
+//	page     page number, starting at 1 (default 1)
+//	per_page posts per page, up to 100 (default 20)
+//	tag      only posts with this tag
+//	from, to only posts published in this range of dates (YYYY-MM-DD, inclusive)
+func (b *Blog) handleAPIPosts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, err := intParam(q.Get("page"), 1)
+	if err != nil || page < 1 {
+		writeAPIError(w, http.StatusBadRequest, "invalid page")
+		return
+	}
+	perPage, err := intParam(q.Get("per_page"), apiDefaultPerPage)
+	if err != nil || perPage < 1 || perPage > apiMaxPerPage {
+		writeAPIError(w, http.StatusBadRequest, "invalid per_page: must be between 1 and "+strconv.Itoa(apiMaxPerPage))
+		return
+	}
+	from, err := dateParam(q.Get("from"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid from: dates must be YYYY-MM-DD")
+		return
+	}
+	to, err := dateParam(q.Get("to"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid to: dates must be YYYY-MM-DD")
+		return
+	}
+
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1) // inclusive of the whole day
+	}
+	snap := b.snapshot()
+	posts := snap.query(QueryOptions{Tag: q.Get("tag"), From: from, To: to})
+
+	list := apiPostList{
+		Posts:      []apiPost{},
+		Page:       page,
+		PerPage:    perPage,
+		Total:      len(posts),
+		TotalPages: (len(posts) + perPage - 1) / perPage,
+	}
+	start := min((page-1)*perPage, len(posts))
+	end := min(start+perPage, len(posts))
+	for _, post := range posts[start:end] {
+		list.Posts = append(list.Posts, b.apiPost(post))
+	}
+
+	b.writeAPIJSON(w, r, snap.modTime, list)
+}
+
+// handleAPIPost returns a single post with its rendered HTML. With ?raw=1 the
+// markdown source (without frontmatter) is included too.
+func (b *Blog) handleAPIPost(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	post, ok := snap.post(r.PathValue("slug"))
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "post not found")
+		return
+	}
+
+	p := b.apiPost(post)
+	p.HTML = string(post.Content)
+	if raw, _ := strconv.ParseBool(r.URL.Query().Get("raw")); raw {
+		p.Markdown = post.markdown
+	}
+	b.writeAPIJSON(w, r, snap.postModTime(post), p)
+}
+
+func (b *Blog) handleAPITags(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	tags := snap.tags
+
+	result := make([]apiTag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, apiTag{Name: tag.Name, Slug: tag.Slug, Count: tag.Count})
+	}
+
+	b.writeAPIJSON(w, r, snap.modTime, result)
+}
+
+func (b *Blog) handleAPINotFound(w http.ResponseWriter, r *http.Request) {
+	writeAPIError(w, http.StatusNotFound, "not found")
+}
+
+// writeAPIJSON writes v as a successful response, with the caching headers of
+// a page last modified at modTime.
+func (b *Blog) writeAPIJSON(w http.ResponseWriter, r *http.Request, modTime time.Time, v any) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "encoding response: "+err.Error())
+		return
+	}
+	b.writePage(w, r, newPage(out, "application/json; charset=utf-8", modTime), b.config.CacheControl.API)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	out, _ := json.Marshal(apiError{Error: message})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(out)
+}
+
+func intParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func dateParam(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
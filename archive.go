@@ -0,0 +1,129 @@
+package glogger
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ArchiveYear groups the posts published in a year, by month.
+type ArchiveYear struct {
+	Year   int
+	Count  int
+	Months []ArchiveMonth // newest first
+}
+
+// ArchiveMonth holds the posts published in a month.
+type ArchiveMonth struct {
+	Year  int
+	Month time.Month
+	Count int
+	Posts []Post // newest first
+}
+
+// Path returns the month's archive path relative to the blog, e.g. "/_archive/2025/03".
+func (m ArchiveMonth) Path() string {
+	return fmt.Sprintf("/_archive/%04d/%02d", m.Year, int(m.Month))
+}
+
+// buildArchive groups posts (sorted newest first) by year and month. Posts
+// without a publish date aren't included.
+func buildArchive(posts []Post) []ArchiveYear {
+	var years []ArchiveYear
+	for _, post := range posts {
+		if post.PublishDate.IsZero() {
+			continue
+		}
+		year, month := post.PublishDate.Year(), post.PublishDate.Month()
+
+		if len(years) == 0 || years[len(years)-1].Year != year {
+			years = append(years, ArchiveYear{Year: year})
+		}
+		y := &years[len(years)-1]
+		if len(y.Months) == 0 || y.Months[len(y.Months)-1].Month != month {
+			y.Months = append(y.Months, ArchiveMonth{Year: year, Month: month})
+		}
+		m := &y.Months[len(y.Months)-1]
+
+		y.Count++
+		m.Count++
+		m.Posts = append(m.Posts, post)
+	}
+	return years
+}
+
+// Archive returns the published posts grouped by year and month, newest first.
+// Posts without a date aren't included.
+func (b *Blog) Archive() []ArchiveYear {
+	years := slices.Clone(b.snapshot().archive)
+	for i := range years {
+		years[i].Months = slices.Clone(years[i].Months)
+		for j := range years[i].Months {
+			years[i].Months[j].Posts = slices.Clone(years[i].Months[j].Posts)
+		}
+	}
+	return years
+}
+
+func (b *Blog) handleArchiveIndex(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	p, err := snap.pages.render("/_archive/", htmlContentType, snap.modTime, func() (string, error) {
+		return b.renderer.renderArchive(ArchiveTemplateData{Years: snap.archive})
+	})
+	if err != nil {
+		http.Error(w, "Error rendering archive: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Pages)
+}
+
+func (b *Blog) handleArchivePeriod(w http.ResponseWriter, r *http.Request) {
+	year, err := strconv.Atoi(r.PathValue("year"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	month := 0
+	if s := r.PathValue("month"); s != "" {
+		month, err = strconv.Atoi(s)
+		if err != nil || month < 1 || month > 12 {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	snap := b.snapshot()
+	var posts []Post
+	for _, y := range snap.archive {
+		if y.Year != year {
+			continue
+		}
+		for _, m := range y.Months {
+			if month == 0 || int(m.Month) == month {
+				posts = append(posts, m.Posts...)
+			}
+		}
+	}
+	if len(posts) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	period := strconv.Itoa(year)
+	key := fmt.Sprintf("/_archive/%04d", year)
+	if month != 0 {
+		period = time.Month(month).String() + " " + period
+		key += fmt.Sprintf("/%02d", month)
+	}
+
+	p, err := snap.pages.render(key, htmlContentType, snap.modTime, func() (string, error) {
+		return b.renderer.renderPostList(ListTemplateData{Posts: posts, Archive: period})
+	})
+	if err != nil {
+		http.Error(w, "Error rendering archive: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Pages)
+}
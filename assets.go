@@ -0,0 +1,48 @@
+package glogger
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+)
+
+// vendorFS holds the third-party assets served under /_assets/ in Offline
+// mode: the DejaVu Sans Mono font. See assets/vendor/README.md.
+//
+//go:embed assets/vendor
+var vendorFS embed.FS
+
+const vendorDir = "assets/vendor/"
+
+var assetContentTypes = map[string]string{
+	".css": "text/css; charset=utf-8",
+	".ttf": "font/ttf",
+}
+
+func (b *Blog) handleAsset(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+	contentType, ok := assetContentTypes[path.Ext(name)]
+	if !ok || !fs.ValidPath(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	p, err := assetPage(vendorFS, vendorDir+name, contentType)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Assets)
+}
+
+// checkOfflineSyntaxTheme reports an error if there's no chroma style for
+// SyntaxTheme. Offline mode always highlights code server-side, so a theme
+// that only exists in highlight.js would otherwise be silently replaced.
+func checkOfflineSyntaxTheme(config Config) error {
+	if _, ok := lookupChromaStyle(config.SyntaxTheme); !ok {
+		return fmt.Errorf("offline mode: no server-side syntax style for SyntaxTheme %q", config.SyntaxTheme)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// glogger search widget: instant results for every form[data-glogger-search]
+// using the pre-built index at data-index. Without JS the form still submits
+// to the server-side search page.
+(function () {
+    "use strict";
+
+    var script = document.currentScript;
+    var indexURL = script && script.dataset.index;
+    var index = null;
+
+    // keep in sync with stopWords in search.go
+    var stopWords = {};
+    ("a an and are as at be but by for if in into is it of on or so that the " +
+        "their then there these this to was were will with").split(" ").forEach(function (w) {
+        stopWords[w] = true;
+    });
+
+    // keep in sync with undouble in search.go
+    function undouble(word) {
+        var n = word.length;
+        if (n > 3 && word[n - 1] === word[n - 2] && "aeiouylsz".indexOf(word[n - 1]) < 0) {
+            return word.slice(0, -1);
+        }
+        return word;
+    }
+
+    // keep in sync with stem in search.go
+    function stem(word) {
+        var n = word.length;
+        if (/ies$/.test(word) && n > 4) return word.slice(0, -3) + "y";
+        if (/ing$/.test(word) && n > 5) return undouble(word.slice(0, -3));
+        if (/ed$/.test(word) && n > 4) return undouble(word.slice(0, -2));
+        if (/ly$/.test(word) && n > 4) return word.slice(0, -2);
+        if (/[sxz]es$/.test(word) && n > 4) return word.slice(0, -2);
+        if (/s$/.test(word) && !/ss$/.test(word) && n > 3) return word.slice(0, -1);
+        return word;
+    }
+
+    // keep in sync with tokenize in search.go
+    function tokenize(text) {
+        var words = text.toLowerCase().match(/[\p{L}\p{N}]+/gu) || [];
+        return words.filter(function (w) {
+            return w.length >= 2 && !stopWords[w];
+        }).map(stem);
+    }
+
+    function loadIndex() {
+        if (!index) {
+            index = fetch(indexURL).then(function (res) {
+                return res.json();
+            });
+        }
+        return index;
+    }
+
+    // search returns the docs containing every query term, treating the last
+    // term as a prefix so results update while typing
+    function search(docs, query) {
+        var terms = tokenize(query);
+        if (!terms.length) return [];
+        // the last word is only a prefix if it produced the last term,
+        // i.e. it wasn't dropped as a stop word or for being too short
+        var words = query.toLowerCase().match(/[\p{L}\p{N}]+/gu) || [];
+        var partial = words[words.length - 1];
+        if (!tokenize(partial).length) partial = "";
+
+        return docs.map(function (doc) {
+            var score = 0;
+            var title = tokenize(doc.title);
+            for (var i = 0; i < terms.length; i++) {
+                var last = i === terms.length - 1;
+                var hit = doc.terms.some(function (t) {
+                    return t === terms[i] || (last && partial && t.indexOf(partial) === 0);
+                });
+                if (!hit) return null;
+                score += title.indexOf(terms[i]) >= 0 ? 5 : 1;
+            }
+            return { doc: doc, score: score };
+        }).filter(Boolean).sort(function (a, b) {
+            return b.score - a.score;
+        }).slice(0, 10);
+    }
+
+    function attach(form) {
+        var input = form.querySelector("input[name=q]");
+        if (!input) return;
+
+        var list = document.createElement("ul");
+        list.className = "search-results";
+        list.hidden = true;
+        form.appendChild(list);
+
+        input.addEventListener("focus", loadIndex, { once: true });
+        input.addEventListener("input", function () {
+            var query = input.value;
+            loadIndex().then(function (docs) {
+                if (query !== input.value) return;
+                var results = search(docs, query);
+                list.replaceChildren();
+                results.forEach(function (r) {
+                    var item = document.createElement("li");
+                    var link = document.createElement("a");
+                    link.href = r.doc.url;
+                    link.textContent = r.doc.title;
+                    item.appendChild(link);
+                    if (r.doc.description) {
+                        var desc = document.createElement("div");
+                        desc.className = "search-result-description";
+                        desc.textContent = r.doc.description;
+                        item.appendChild(desc);
+                    }
+                    list.appendChild(item);
+                });
+                list.hidden = results.length === 0;
+            });
+        });
+    }
+
+    if (indexURL) {
+        document.querySelectorAll("form[data-glogger-search]").forEach(attach);
+    }
+})();
@@ -1,30 +1,49 @@
 package glogger
 
 import (
-	"io/fs"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
-	"path/filepath"
+	"path"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
 	"github.com/yuin/goldmark/parser"
 )
 
 type Blog struct {
 	config   Config
-	posts    []Post
 	renderer *templateRenderer
 	md       goldmark.Markdown
+
+	current  atomic.Pointer[snapshot]
+	reloadMu sync.Mutex // serialises Initialize so reloads publish in order
+
+	stopWatch context.CancelFunc
 }
 
 func New(config Config) (*Blog, error) {
 	config.setDefaults()
+	config.TagAliases = lowerKeys(config.TagAliases)
 
 	if !validateTheme(config.Theme) {
 		config.Theme = "default"
 	}
 
+	if config.Offline {
+		if err := checkOfflineSyntaxTheme(config); err != nil {
+			return nil, err
+		}
+	}
+
 	renderer, err := newTemplateRenderer(config)
 	if err != nil {
 		return nil, err
@@ -32,60 +51,129 @@ func New(config Config) (*Blog, error) {
 
 	b := &Blog{
 		config:   config,
-		posts:    []Post{},
 		renderer: renderer,
-		md:       newMarkdown(),
+		md:       newMarkdown(config),
 	}
 
 	if err := b.Initialize(); err != nil {
 		return nil, err
 	}
 
+	if config.Watch {
+		ctx, cancel := context.WithCancel(context.Background())
+		b.stopWatch = cancel
+		go b.Watch(ctx)
+	}
+
 	return b, nil
 }
 
+// Close stops the background watcher started by Config.Watch.
+// It is safe to call on a blog that isn't watching.
+func (b *Blog) Close() error {
+	if b.stopWatch != nil {
+		b.stopWatch()
+	}
+	return nil
+}
+
+// Initialize (re)loads all posts from the content source. The new post set is
+// built separately and swapped in once complete, so it is safe to call while
+// the blog is serving requests. On error the currently loaded posts are kept.
 func (b *Blog) Initialize() error {
-	b.posts = []Post{}
+	b.reloadMu.Lock()
+	defer b.reloadMu.Unlock()
 
-	err := filepath.Walk(b.config.ContentDir, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	// read the version before the documents so that a change made while
+	// loading is picked up by the next Watch poll rather than missed
+	version, versioned, err := sourceVersion(b.config.Source)
+	if err != nil {
+		return err
+	}
 
-		if info.IsDir() || !strings.HasSuffix(path, ".md") {
-			return nil
-		}
+	docs, err := b.config.Source.Documents()
+	if err != nil {
+		return err
+	}
+	if !versioned {
+		version = documentsVersion(docs)
+	}
 
-		post, err := parsePost(path, b.md)
+	posts := []Post{}
+	paths := map[string]string{} // slug -> document path, to report duplicates
+	for _, doc := range docs {
+		post, err := parseDocument(doc, b.md)
 		if err != nil {
 			return err
 		}
 
 		if post.Draft {
-			return nil
+			continue
 		}
+		post.Tags = normalizeTags(post.Tags, b.config.TagAliases)
 
-		filename := filepath.Base(path)
-		post.Slug = strings.TrimSuffix(filename, filepath.Ext(filename))
+		if post.Slug == "" {
+			post.Slug = b.slugFromPath(doc.Path)
+		}
+		if err := validateSlug(post.Slug); err != nil {
+			return fmt.Errorf("invalid slug %q in %s: %w", post.Slug, doc.Path, err)
+		}
+		if prev, ok := paths[post.Slug]; ok {
+			return fmt.Errorf("duplicate slug %q: %s and %s", post.Slug, prev, doc.Path)
+		}
+		paths[post.Slug] = doc.Path
 
-		b.posts = append(b.posts, post)
-		return nil
-	})
-	if err != nil {
-		return err
+		posts = append(posts, post)
 	}
 
-	sort.Slice(b.posts, func(i, j int) bool {
-		return b.posts[i].PublishDate.After(b.posts[j].PublishDate)
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].PublishDate.After(posts[j].PublishDate)
 	})
 
+	snap := newSnapshot(posts, version)
+	snap.published(b.current.Load(), time.Now())
+	snap.pages = newPageCache(b.config.PageCacheMaxBytes, b.config.Encoders)
+	b.current.Store(snap)
+
 	return nil
 }
 
+// slugFromPath derives a post's slug from its document path: the filename
+// without extension, or the whole relative path when NestedSlugs is set.
+func (b *Blog) slugFromPath(p string) string {
+	p = strings.TrimSuffix(p, path.Ext(p))
+	if b.config.NestedSlugs {
+		return p
+	}
+	return path.Base(p)
+}
+
+// reservedDirs are the first path segments of the blog's own routes; a post
+// under one of them would be shadowed by, or shadow, those routes.
+var reservedDirs = []string{"_tags", "_archive", "_search", "_api", "_themes", "_assets"}
+
+// reservedPaths are the top-level routes that a post at the same path would
+// be shadowed by.
+var reservedPaths = []string{"feed.xml", "atom.xml", "feed.json", "sitemap.xml"}
+
+// validateSlug reports whether slug collides with one of the blog's own
+// routes, which would make the post unreachable.
+func validateSlug(slug string) error {
+	segments := strings.Split(slug, "/")
+	switch {
+	case slices.Contains(reservedDirs, segments[0]):
+		return fmt.Errorf("paths under %s/ are reserved for the blog's own routes", segments[0])
+	case segments[0] == "page" && len(segments) == 2:
+		return errors.New("page/{n} is reserved for pagination")
+	case slices.Contains(reservedPaths, slug):
+		return fmt.Errorf("%s is reserved for the blog's own routes", slug)
+	}
+	return nil
+}
+
+// GetPosts returns a copy of the loaded posts, newest first.
 func (b *Blog) GetPosts() []Post {
-	result := make([]Post, len(b.posts))
-	copy(result, b.posts)
-	return result
+	return copyPosts(b.snapshot().posts)
 }
 
 func (b *Blog) URLPrefix() string {
@@ -103,8 +191,18 @@ func (b *Blog) Mount(mux *http.ServeMux) {
 	mux.Handle(prefix+"/", http.StripPrefix(prefix, b.Handler()))
 }
 
-func newMarkdown() goldmark.Markdown {
+// newMarkdown returns the markdown pipeline posts are rendered with. With
+// ServerHighlight set, fenced code blocks are highlighted as they're parsed,
+// as spans with CSS classes styled by syntaxCSS.
+func newMarkdown(config Config) goldmark.Markdown {
+	var extensions []goldmark.Extender
+	if config.ServerHighlight {
+		extensions = append(extensions, highlighting.NewHighlighting(
+			highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+		))
+	}
 	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 		),
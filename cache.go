@@ -0,0 +1,168 @@
+package glogger
+
+import (
+	"container/list"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const htmlContentType = "text/html; charset=utf-8"
+
+// page is a rendered response body. Pages are rendered once per snapshot and
+// never modified afterwards, apart from remembering their compressed forms.
+type page struct {
+	body        []byte
+	contentType string
+	etag        string
+	modTime     time.Time // zero if unknown
+
+	mu       sync.Mutex
+	variants map[string][]byte // content coding -> compressed body; nil if compression didn't help
+}
+
+func newPage(body []byte, contentType string, modTime time.Time) *page {
+	h := fnv.New64a()
+	h.Write(body)
+	return &page{
+		body:        body,
+		contentType: contentType,
+		etag:        `"` + strconv.FormatUint(h.Sum64(), 16) + `"`,
+		modTime:     modTime,
+	}
+}
+
+// encoded returns the body compressed with enc, compressing it only the first
+// time. It returns nil if the body should be sent uncompressed: compression
+// failed or didn't make it any smaller.
+func (p *page) encoded(enc Encoder) []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if body, ok := p.variants[enc.Encoding()]; ok {
+		return body
+	}
+	body, err := enc.Encode(p.body)
+	if err != nil || len(body) >= len(p.body) {
+		body = nil
+	}
+	if p.variants == nil {
+		p.variants = map[string][]byte{}
+	}
+	p.variants[enc.Encoding()] = body
+	return body
+}
+
+// size returns the memory used by the page's body and compressed variants.
+func (p *page) size() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.body)
+	for _, body := range p.variants {
+		n += len(body)
+	}
+	return int64(n)
+}
+
+// pageCache holds the pages rendered from one snapshot, keyed by canonical
+// path. Each snapshot gets a fresh cache, so publishing new posts invalidates
+// every cached page at once.
+//
+// Pages are compressed with each of encoders before they're cached, so
+// compression happens once per page rather than once per request.
+//
+// When maxBytes is positive the least recently used pages are evicted to keep
+// the total size of the cached bodies under it. A nil *pageCache caches nothing.
+type pageCache struct {
+	maxBytes int64
+	encoders []Encoder
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element // key -> element holding a *cacheEntry
+	lru     *list.List               // most recently used at the front
+}
+
+type cacheEntry struct {
+	key  string
+	page *page
+	size int64
+}
+
+// newPageCache returns a cache bounded by maxBytes, with 0 meaning no bound.
+// It returns nil, disabling caching, if maxBytes is negative.
+func newPageCache(maxBytes int64, encoders []Encoder) *pageCache {
+	if maxBytes < 0 {
+		return nil
+	}
+	return &pageCache{
+		maxBytes: maxBytes,
+		encoders: encoders,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+// render returns the page cached under key, calling fn to render it on a miss.
+// An empty key renders without caching, for pages that shouldn't take up
+// space in the cache (e.g. a tag nobody has used).
+//
+// Concurrent misses on the same key may each render the page; the results
+// are identical, so the last one simply replaces the others.
+func (c *pageCache) render(key, contentType string, modTime time.Time, fn func() (string, error)) (*page, error) {
+	if p, ok := c.get(key); ok {
+		return p, nil
+	}
+	body, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	p := newPage([]byte(body), contentType, modTime)
+	c.add(key, p)
+	return p, nil
+}
+
+func (c *pageCache) get(key string) (*page, bool) {
+	if c == nil || key == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheEntry).page, true
+}
+
+func (c *pageCache) add(key string, p *page) {
+	if c == nil || key == "" {
+		return
+	}
+	for _, enc := range c.encoders {
+		p.encoded(enc)
+	}
+	size := p.size()
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, page: p, size: size})
+	c.size += size
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove drops e from the cache. c.mu must be held.
+func (c *pageCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
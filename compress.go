@@ -0,0 +1,87 @@
+package glogger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strconv"
+	"strings"
+)
+
+// Encoder compresses response bodies with one HTTP content coding. glogger
+// ships with gzip; implement Encoder to offer others, such as brotli:
+//
+//	type brotliEncoder struct{}
+//
+//	func (brotliEncoder) Encoding() string { return "br" }
+//
+//	func (brotliEncoder) Encode(data []byte) ([]byte, error) {
+//		var buf bytes.Buffer
+//		w := brotli.NewWriterLevel(&buf, brotli.DefaultCompression)
+//		if _, err := w.Write(data); err != nil {
+//			return nil, err
+//		}
+//		err := w.Close()
+//		return buf.Bytes(), err
+//	}
+type Encoder interface {
+	Encoding() string                   // Content-Encoding token, e.g. "gzip" or "br"
+	Encode(data []byte) ([]byte, error) // compressed form of data
+}
+
+// GzipEncoder returns an Encoder that compresses with gzip at the given
+// level (e.g. gzip.BestCompression).
+func GzipEncoder(level int) Encoder {
+	return gzipEncoder{level: level}
+}
+
+type gzipEncoder struct {
+	level int
+}
+
+func (gzipEncoder) Encoding() string { return "gzip" }
+
+func (e gzipEncoder) Encode(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, e.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// negotiateEncoding returns the first of encoders that the Accept-Encoding
+// header allows, or nil if the response should be sent uncompressed.
+func negotiateEncoding(acceptEncoding string, encoders []Encoder) Encoder {
+	if acceptEncoding == "" {
+		return nil
+	}
+
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(coding))] = q
+	}
+
+	for _, enc := range encoders {
+		q, ok := accepted[enc.Encoding()]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			return enc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package glogger
+
+import (
+	"bytes"
+	"io/fs"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// writePage writes p to w along with its ETag, Last-Modified and the given
+// Cache-Control header, compressed with the best encoding the client accepts.
+// Conditional requests (If-None-Match, If-Modified-Since) that match are
+// answered with 304 Not Modified.
+func (b *Blog) writePage(w http.ResponseWriter, r *http.Request, p *page, cacheControl string) {
+	h := w.Header()
+	h.Set("Content-Type", p.contentType)
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+
+	body, etag := p.body, p.etag
+	if len(b.config.Encoders) > 0 {
+		h.Add("Vary", "Accept-Encoding")
+		if enc := negotiateEncoding(r.Header.Get("Accept-Encoding"), b.config.Encoders); enc != nil {
+			if encoded := p.encoded(enc); encoded != nil {
+				// Each encoding is a different representation, so needs its own ETag.
+				body = encoded
+				etag = strings.TrimSuffix(p.etag, `"`) + "-" + enc.Encoding() + `"`
+				h.Set("Content-Encoding", enc.Encoding())
+			}
+		}
+	}
+	h.Set("ETag", etag)
+
+	http.ServeContent(w, r, "", p.modTime, bytes.NewReader(body))
+}
+
+func (c *CachePolicy) setDefaults() {
+	if c.Pages == "" {
+		c.Pages = "no-cache"
+	}
+	if c.Feeds == "" {
+		c.Feeds = "no-cache"
+	}
+	if c.Assets == "" {
+		c.Assets = "public, max-age=86400"
+	}
+	if c.API == "" {
+		c.API = "no-cache"
+	}
+}
+
+// buildTime is the Last-Modified date of embedded assets. Assets only change
+// when the binary is rebuilt, so this is the commit time recorded in the build
+// info, or when the process started for builds without one.
+var buildTime = readBuildTime()
+
+func readBuildTime() time.Time {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return time.Now()
+	}
+	var commit time.Time
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.time":
+			commit, _ = time.Parse(time.RFC3339, s.Value)
+		case "vcs.modified":
+			if s.Value == "true" {
+				// Uncommitted changes may be newer than the commit.
+				return time.Now()
+			}
+		}
+	}
+	if commit.IsZero() {
+		return time.Now()
+	}
+	return commit
+}
+
+var assetPages sync.Map // embedded file name or other key -> *page
+
+// assetPage returns the embedded file name as a page, reading it only once.
+func assetPage(fsys fs.FS, name, contentType string) (*page, error) {
+	return staticPage(name, contentType, func() ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	})
+}
+
+// staticPage returns the page stored under key, calling load to create it the
+// first time. Static pages only change when the binary is rebuilt.
+func staticPage(key, contentType string, load func() ([]byte, error)) (*page, error) {
+	if p, ok := assetPages.Load(key); ok {
+		return p.(*page), nil
+	}
+	content, err := load()
+	if err != nil {
+		return nil, err
+	}
+	p, _ := assetPages.LoadOrStore(key, newPage(content, contentType, buildTime))
+	return p.(*page), nil
+}
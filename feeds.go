@@ -0,0 +1,384 @@
+package glogger
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+	GUID        string `xml:"guid"`
+	Content     string `xml:"content:encoded,omitempty"`
+}
+
+type rssChannel struct {
+	XMLName       xml.Name  `xml:"channel"`
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName   xml.Name `xml:"rss"`
+	Version   string   `xml:"version,attr"`
+	ContentNS string   `xml:"xmlns:content,attr,omitempty"`
+	Channel   rssChannel
+}
+
+const rssContentType = "application/rss+xml; charset=utf-8"
+
+func (b *Blog) handleFeed(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	b.writeFeed(w, r, snap, "/feed.xml", rssContentType, func() (string, error) {
+		return b.renderRSS(snap.posts, b.config.Title, b.absURL(""))
+	})
+}
+
+func (b *Blog) handleTagFeed(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	slug := tagSlug(r.PathValue("tag"))
+	posts := snap.postsWithTag(slug)
+
+	// Any tag name can be requested, so only cache the feeds of tags in use.
+	key := ""
+	if len(posts) > 0 {
+		key = "/_tags/" + slug + "/feed.xml"
+	}
+	b.writeFeed(w, r, snap, key, rssContentType, func() (string, error) {
+		title := b.config.Title + " — Posts tagged: " + snap.tagLabel(slug)
+		return b.renderRSS(posts, title, b.absURL("/_tags/"+slug))
+	})
+}
+
+// writeFeed writes the feed cached under key, calling render to generate it
+// on a miss.
+func (b *Blog) writeFeed(w http.ResponseWriter, r *http.Request, snap *snapshot, key, contentType string, render func() (string, error)) {
+	p, err := snap.pages.render(key, contentType, snap.modTime, render)
+	if err != nil {
+		http.Error(w, "Error generating feed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Feeds)
+}
+
+// renderRSS renders an RSS 2.0 feed of posts, capped at FeedLimit.
+func (b *Blog) renderRSS(posts []Post, title, link string) (string, error) {
+	posts = b.limitFeed(posts)
+
+	items := make([]rssItem, 0, len(posts))
+	for _, post := range posts {
+		postLink := b.absURL("/" + post.Slug)
+		pubDate := ""
+		if !post.PublishDate.IsZero() {
+			pubDate = post.PublishDate.UTC().Format(time.RFC1123Z)
+		}
+		item := rssItem{
+			Title:       post.Title,
+			Link:        postLink,
+			Description: post.Description,
+			PubDate:     pubDate,
+			GUID:        postLink,
+		}
+		if b.config.FeedFullContent {
+			item.Content = absoluteLinks(string(post.Content), postLink)
+		}
+		items = append(items, item)
+	}
+
+	lastBuild := ""
+	if len(posts) > 0 && !posts[0].PublishDate.IsZero() {
+		lastBuild = posts[0].PublishDate.UTC().Format(time.RFC1123Z)
+	}
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         title,
+			Link:          link,
+			Description:   b.config.Description,
+			LastBuildDate: lastBuild,
+			Items:         items,
+		},
+	}
+	if b.config.FeedFullContent {
+		feed.ContentNS = "http://purl.org/rss/1.0/modules/content/"
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+func (b *Blog) handleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	b.writeFeed(w, r, snap, "/atom.xml", "application/atom+xml; charset=utf-8", func() (string, error) {
+		return b.renderAtom(snap.posts)
+	})
+}
+
+// renderAtom renders an Atom feed of posts, capped at FeedLimit.
+func (b *Blog) renderAtom(posts []Post) (string, error) {
+	posts = b.limitFeed(posts)
+
+	var updated time.Time
+	entries := make([]atomEntry, 0, len(posts))
+	for _, post := range posts {
+		link := b.absURL("/" + post.Slug)
+		postUpdated := post.lastUpdated()
+		if postUpdated.IsZero() {
+			// undated posts still need an <updated>; use the file's
+			postUpdated = post.lastModified()
+		}
+		if postUpdated.After(updated) {
+			updated = postUpdated
+		}
+
+		entry := atomEntry{
+			Title:   post.Title,
+			ID:      b.atomID("/" + post.Slug),
+			Link:    atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Updated: formatAtomDate(postUpdated),
+		}
+		if !post.PublishDate.IsZero() {
+			entry.Published = formatAtomDate(post.PublishDate)
+		}
+		if post.Author != "" {
+			entry.Author = &atomPerson{Name: post.Author}
+		}
+		for _, tag := range post.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		if post.Description != "" {
+			entry.Summary = &atomText{Type: "text", Body: post.Description}
+		}
+		if b.config.FeedFullContent {
+			entry.Content = &atomText{Type: "html", Body: absoluteLinks(string(post.Content), link)}
+		}
+		entries = append(entries, entry)
+	}
+
+	author := b.config.Author
+	if author == "" {
+		author = b.config.Title
+	}
+
+	feed := atomFeed{
+		Title:    b.config.Title,
+		Subtitle: b.config.Description,
+		ID:       b.atomID("/"),
+		Updated:  formatAtomDate(updated),
+		Links: []atomLink{
+			{Href: b.absURL("/atom.xml"), Rel: "self", Type: "application/atom+xml"},
+			{Href: b.absURL("/"), Rel: "alternate", Type: "text/html"},
+		},
+		Author:  atomPerson{Name: author},
+		Entries: entries,
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+func (b *Blog) handleJSONFeed(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	b.writeFeed(w, r, snap, "/feed.json", "application/feed+json; charset=utf-8", func() (string, error) {
+		return b.renderJSONFeed(snap.posts)
+	})
+}
+
+// renderJSONFeed renders a JSON Feed of posts, capped at FeedLimit.
+func (b *Blog) renderJSONFeed(posts []Post) (string, error) {
+	posts = b.limitFeed(posts)
+
+	items := make([]jsonFeedItem, 0, len(posts))
+	for _, post := range posts {
+		link := b.absURL("/" + post.Slug)
+		item := jsonFeedItem{
+			ID:          link,
+			URL:         link,
+			Title:       post.Title,
+			ContentHTML: absoluteLinks(string(post.Content), link),
+			Summary:     post.Description,
+			Tags:        post.Tags,
+		}
+		if !post.PublishDate.IsZero() {
+			item.DatePublished = formatAtomDate(post.PublishDate)
+		}
+		if !post.Updated.IsZero() {
+			item.DateModified = formatAtomDate(post.Updated)
+		}
+		if post.Author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: post.Author}}
+		}
+		items = append(items, item)
+	}
+
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       b.config.Title,
+		HomePageURL: b.absURL("/"),
+		FeedURL:     b.absURL("/feed.json"),
+		Description: b.config.Description,
+		Items:       items,
+	}
+	if b.config.Author != "" {
+		feed.Authors = []jsonFeedAuthor{{Name: b.config.Author}}
+	}
+
+	out, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// limitFeed returns the newest posts to include in a feed, capped at FeedLimit.
+func (b *Blog) limitFeed(posts []Post) []Post {
+	if b.config.FeedLimit > 0 && len(posts) > b.config.FeedLimit {
+		posts = posts[:b.config.FeedLimit]
+	}
+	return posts
+}
+
+var linkAttrPattern = regexp.MustCompile(`(\s(?:href|src)=")([^"]*)(")`)
+
+// absoluteLinks rewrites relative href and src attributes in rendered post
+// HTML so they resolve against the post's URL. Feed readers display content
+// away from the blog, where relative links and images would otherwise break.
+func absoluteLinks(html, postURL string) string {
+	base, err := url.Parse(postURL)
+	if err != nil {
+		return html
+	}
+	return linkAttrPattern.ReplaceAllStringFunc(html, func(attr string) string {
+		m := linkAttrPattern.FindStringSubmatch(attr)
+		ref, err := url.Parse(m[2])
+		if err != nil || ref.IsAbs() {
+			return attr
+		}
+		return m[1] + base.ResolveReference(ref).String() + m[3]
+	})
+}
+
+// formatAtomDate formats t as an RFC 3339 timestamp, as used by Atom and JSON
+// Feed. Atom requires a timestamp on every feed and entry, so undated posts get
+// the zero time there.
+func formatAtomDate(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// atomID returns the permanent, universally unique id Atom requires for
+// the feed or entry at path: its URL when BaseURL is set, otherwise a
+// name-based UUID URN derived from the blog's title and the path, since a
+// relative URL is neither.
+func (b *Blog) atomID(path string) string {
+	if b.config.BaseURL != "" {
+		return b.absURL(path)
+	}
+	return nameUUID(b.config.Title + b.config.URLPrefix + path)
+}
+
+// urlNamespace is the RFC 9562 namespace for name-based UUIDs of URLs.
+var urlNamespace = [16]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// nameUUID returns the version 5 (SHA-1) UUID of name as a urn:uuid URI.
+func nameUUID(name string) string {
+	h := sha1.New()
+	h.Write(urlNamespace[:])
+	h.Write([]byte(name))
+	u := h.Sum(nil)[:16]
+	u[6] = u[6]&0x0f | 0x50 // version 5
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// absURL returns the URL of path within the blog, made absolute with BaseURL
+// when one is configured.
+func (b *Blog) absURL(path string) string {
+	return strings.TrimRight(b.config.BaseURL, "/") + b.config.URLPrefix + path
+}
@@ -25,8 +25,25 @@ const (
 // blog.Mount(mux)  // registers all routes under URLPrefix
 //
 // assuming default conf, this will set up these routes (relative to prefix)
-//   - GET /                    — post list
-//   - GET /feed.xml            — RSS 2.0 feed
-//   - GET /{slug}              — individual post
-//   - GET /_tags/{tag}         — posts filtered by tag
-//   - GET /_themes/{theme}.css — theme CSS
+//   - GET /                           — post list
+//   - GET /page/{n}                   — post list, page n (with PostsPerPage set)
+//   - GET /feed.xml                   — RSS 2.0 feed
+//   - GET /atom.xml                   — Atom 1.0 feed
+//   - GET /feed.json                  — JSON Feed 1.1
+//   - GET /sitemap.xml                — sitemap of the index, posts and tag pages
+//   - GET /{slug...}                  — individual post (slugs may contain "/" with NestedSlugs)
+//   - GET /_tags/                     — all tags with post counts
+//   - GET /_tags/{tag}                — posts filtered by tag
+//   - GET /_tags/{tag}/page/{n}       — posts filtered by tag, page n
+//   - GET /_tags/{tag}/feed.xml       — RSS 2.0 feed of posts with a tag
+//   - GET /_archive/                  — years and months with post counts
+//   - GET /_archive/{year}            — posts published in a year
+//   - GET /_archive/{year}/{month}    — posts published in a month
+//   - GET /_search?q={query}          — full-text search
+//   - GET /_search/index.json         — pre-tokenized index for client-side search
+//   - GET /_api/posts                 — JSON: posts, filterable by tag and date
+//   - GET /_api/posts/{slug...}       — JSON: a single post
+//   - GET /_api/tags                  — JSON: tags with post counts
+//   - GET /_themes/{theme}.css        — theme CSS
+//   - GET /_themes/syntax/{style}.css — syntax highlighting CSS (with ServerHighlight)
+//   - GET /_assets/{path...}          — bundled fonts (Offline mode)
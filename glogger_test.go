@@ -1,19 +1,34 @@
 package glogger
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func TestParsePost(t *testing.T) {
-	md := newMarkdown()
+	md := newMarkdown(Config{})
 
 	t.Run("valid frontmatter", func(t *testing.T) {
-		f := writeTempPost(t, `---
+		fsys, name := memPost(`---
 title: "Hello World"
 date: 2025-01-15
 description: "A test post"
@@ -23,7 +38,7 @@ draft: false
 
 Post body here.
 `)
-		post, err := parsePost(f, md)
+		post, err := parsePost(fsys, name, md)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -48,8 +63,8 @@ Post body here.
 	})
 
 	t.Run("missing title default to Untitled Post", func(t *testing.T) {
-		f := writeTempPost(t, "---\ndate: 2025-01-01\n---\n\nBody.\n")
-		post, err := parsePost(f, md)
+		fsys, name := memPost("---\ndate: 2025-01-01\n---\n\nBody.\n")
+		post, err := parsePost(fsys, name, md)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -59,24 +74,24 @@ Post body here.
 	})
 
 	t.Run("missing frontmatter delimiter returns error", func(t *testing.T) {
-		f := writeTempPost(t, "# no frontmatter here\n\nblah blah.\n")
-		_, err := parsePost(f, md)
+		fsys, name := memPost("# no frontmatter here\n\nblah blah.\n")
+		_, err := parsePost(fsys, name, md)
 		if err == nil {
 			t.Error("expected error for missing frontmatter")
 		}
 	})
 
 	t.Run("incomplete frontmatter returns error", func(t *testing.T) {
-		f := writeTempPost(t, "---\ntitle: only one delimiter\n")
-		_, err := parsePost(f, md)
+		fsys, name := memPost("---\ntitle: only one delimiter\n")
+		_, err := parsePost(fsys, name, md)
 		if err == nil {
 			t.Error("expected error for incomplete frontmatter")
 		}
 	})
 
 	t.Run("invalid date is ignored", func(t *testing.T) {
-		f := writeTempPost(t, "---\ntitle: Test\ndate: not-a-date\n---\n\nBody.\n")
-		post, err := parsePost(f, md)
+		fsys, name := memPost("---\ntitle: Test\ndate: not-a-date\n---\n\nBody.\n")
+		post, err := parsePost(fsys, name, md)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -86,8 +101,8 @@ Post body here.
 	})
 
 	t.Run("draft field is parsed", func(t *testing.T) {
-		f := writeTempPost(t, "---\ntitle: Draft\ndraft: true\n---\n\nBody.\n")
-		post, err := parsePost(f, md)
+		fsys, name := memPost("---\ntitle: Draft\ndraft: true\n---\n\nBody.\n")
+		post, err := parsePost(fsys, name, md)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -139,214 +154,1908 @@ func TestInitialize_SortDateDescending(t *testing.T) {
 	}
 }
 
-// themes
+func TestInitialize_ConcurrentWithRequests(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\ndate: 2025-01-01\ntags: [go]\n---\n\nContent.\n")
 
-func TestValidateTheme(t *testing.T) {
-	valid := []string{"default", "dark", "light", "rosepine"}
-	for _, theme := range valid {
-		if !validateTheme(theme) {
-			t.Errorf("expected %q to be valid", theme)
-		}
-	}
-	if validateTheme("invalid") {
-		t.Error("expected \"invalid\" to be invalid")
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", Theme: "default"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-}
+	handler := blog.Handler()
 
-func TestDefaultSyntaxTheme(t *testing.T) {
-	cases := []struct {
-		theme  string
-		syntax string
-	}{
-		{"rosepine", "rose-pine"},
-		{"dark", "github-dark"},
-		{"light", "github"},
-		{"default", "github"},
-		{"unknown", "github"},
+	var wg sync.WaitGroup
+	for _, path := range []string{"/", "/hello", "/_tags/go", "/feed.xml"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+				if w.Code != http.StatusOK {
+					t.Errorf("GET %s: got %d, want %d", path, w.Code, http.StatusOK)
+					return
+				}
+				if !strings.Contains(w.Body.String(), "Hello") {
+					t.Errorf("GET %s: served without posts during reload", path)
+					return
+				}
+			}
+		}()
 	}
-	for _, c := range cases {
-		got := defaultSyntaxTheme(c.theme)
-		if got != c.syntax {
-			t.Errorf("defaultSyntaxTheme(%q): got %q, want %q", c.theme, got, c.syntax)
+	for range 20 {
+		if err := blog.Initialize(); err != nil {
+			t.Fatalf("reload: %v", err)
 		}
 	}
+	wg.Wait()
 }
 
-//handlers
+func TestInitialize_ContentFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"hello.md":        {Data: []byte("---\ntitle: Hello\ndate: 2025-01-01\n---\n\nContent.\n")},
+		"2024/nested.md":  {Data: []byte("---\ntitle: Nested\ndate: 2024-01-01\n---\n\nContent.\n")},
+		"images/logo.png": {Data: []byte("not markdown")},
+	}
 
-func TestHandler_ListPosts(t *testing.T) {
-	blog := blogWithPosts(t, "---\ntitle: Test Post\ndate: 2025-01-01\n---\n\nContent.\n")
+	blog, err := New(Config{ContentFS: fsys, ContentDir: "does-not-exist"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-	req := httptest.NewRequest("GET", "/", nil)
-	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+	posts := blog.GetPosts()
+	if len(posts) != 2 {
+		t.Fatalf("got %d posts, want 2", len(posts))
+	}
+	if posts[1].Slug != "nested" {
+		t.Errorf("slug: got %q, want %q", posts[1].Slug, "nested")
+	}
+}
 
-	if w.Code != http.StatusOK {
-		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+func TestInitialize_ContentSource(t *testing.T) {
+	src := &memSource{docs: []Document{
+		{Path: "hello.md", Content: []byte("---\ntitle: Hello\ndate: 2025-01-01\n---\n\nContent.\n")},
+	}}
+
+	blog, err := New(Config{Source: src, ReloadInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if !strings.Contains(w.Body.String(), "Test Post") {
-		t.Error("expected post title in response body")
+	if posts := blog.GetPosts(); len(posts) != 1 || posts[0].Slug != "hello" {
+		t.Fatalf("expected post loaded from source, got %+v", posts)
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go blog.Watch(ctx)
+
+	src.set(Document{Path: "hello.md", Content: []byte("---\ntitle: Edited\ndate: 2025-01-01\n---\n\nContent.\n")})
+	waitFor(t, func() bool { return blog.GetPosts()[0].Title == "Edited" })
 }
 
-func TestHandler_TaggedPosts(t *testing.T) {
+func TestInitialize_FrontmatterSlug(t *testing.T) {
 	dir := t.TempDir()
-	writePost(t, dir, "tagged.md", "---\ntitle: Tagged Post\ndate: 2025-01-01\ntags: [go, test]\n---\n\nContent.\n")
-	writePost(t, dir, "other.md", "---\ntitle: Other Post\ndate: 2025-01-02\ntags: [rust]\n---\n\nContent.\n")
+	writePost(t, dir, "2025-01-01-hello.md", "---\ntitle: Hello\nslug: hello-world\ndate: 2025-01-01\n---\n\nContent.\n")
 
-	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", Theme: "default"})
+	blog, err := New(Config{ContentDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := blog.GetPosts()[0].Slug; got != "hello-world" {
+		t.Errorf("slug: got %q, want %q", got, "hello-world")
+	}
+}
+
+func TestInitialize_NestedSlugs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"2024/hello.md": {Data: []byte("---\ntitle: Hello 2024\ndate: 2024-01-01\n---\n\nOld content.\n")},
+		"2025/hello.md": {Data: []byte("---\ntitle: Hello 2025\ndate: 2025-01-01\n---\n\nNew content.\n")},
+	}
+
+	blog, err := New(Config{ContentFS: fsys, NestedSlugs: true})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	req := httptest.NewRequest("GET", "/_tags/go", nil)
-	req.SetPathValue("tag", "go")
 	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/2024/hello", nil))
 
 	if w.Code != http.StatusOK {
 		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
 	}
-	body := w.Body.String()
-	if !strings.Contains(body, "Tagged Post") {
-		t.Error("expected tagged post in response")
+	if !strings.Contains(w.Body.String(), "Old content") {
+		t.Error("expected nested post content in response body")
 	}
-	if strings.Contains(body, "Other Post") {
-		t.Error("expected other post to be excluded")
+}
+
+func TestInitialize_DuplicateSlugs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"2024/hello.md": {Data: []byte("---\ntitle: Hello 2024\n---\n\nContent.\n")},
+		"2025/hello.md": {Data: []byte("---\ntitle: Hello 2025\n---\n\nContent.\n")},
 	}
-	if !strings.Contains(body, "Posts tagged: go") {
-		t.Error("expected tag title in response")
+
+	_, err := New(Config{ContentFS: fsys})
+	if err == nil {
+		t.Fatal("expected error for duplicate slugs")
+	}
+	if !strings.Contains(err.Error(), "2024/hello.md") || !strings.Contains(err.Error(), "2025/hello.md") {
+		t.Errorf("expected error to name both files, got %q", err)
 	}
 }
 
-func TestHandler_SinglePost(t *testing.T) {
-	blog := blogWithPosts(t, "---\ntitle: Hello\ndate: 2025-01-01\n---\n\nBody content.\n")
-
-	req := httptest.NewRequest("GET", "/hello", nil)
-	req.SetPathValue("slug", "hello")
-	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+func TestInvalidSlugs(t *testing.T) {
+	cases := []struct {
+		path, slug string
+	}{
+		{"feed.xml.md", ""},
+		{"post.md", "sitemap.xml"},
+		{"post.md", "_tags/go"},
+		{"post.md", "_assets/fonts/x.css"},
+		{"post.md", "_api"},
+		{"post.md", "page/2"},
+	}
+	for _, c := range cases {
+		content := "---\ntitle: Post\n---\n\nContent.\n"
+		if c.slug != "" {
+			content = "---\ntitle: Post\nslug: " + strconv.Quote(c.slug) + "\n---\n\nContent.\n"
+		}
+		fsys := fstest.MapFS{c.path: {Data: []byte(content)}}
+		_, err := New(Config{ContentFS: fsys})
+		if err == nil || !strings.Contains(err.Error(), c.path) {
+			t.Errorf("%s (slug %q): expected error naming the file, got %v", c.path, c.slug, err)
+		}
+	}
 
-	if w.Code != http.StatusOK {
-		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	fsys := fstest.MapFS{
+		"page.md":      {Data: []byte("---\ntitle: Page\n---\n\nContent.\n")},
+		"2025/feed.md": {Data: []byte("---\ntitle: Feed\nslug: 2025/feed.xml\n---\n\nContent.\n")},
+		"drafts.md":    {Data: []byte("---\ntitle: Drafts\nslug: _drafts/post\n---\n\nContent.\n")},
+		"my post.md":   {Data: []byte("---\ntitle: Spaced\n---\n\nContent.\n")},
+		"_index.md":    {Data: []byte("---\ntitle: Index\n---\n\nContent.\n")},
 	}
-	if !strings.Contains(w.Body.String(), "Body content") {
-		t.Error("expected post content in response body")
+	blog, err := New(Config{ContentFS: fsys})
+	if err != nil {
+		t.Fatalf("expected slugs that don't collide with routes to be allowed, got %v", err)
+	}
+	for _, path := range []string{"/page", "/2025/feed.xml", "/_drafts/post", "/my%20post", "/_index"} {
+		w := httptest.NewRecorder()
+		blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", path, w.Code)
+		}
 	}
 }
 
-func TestHandler_SinglePost_NotFound(t *testing.T) {
-	blog := blogWithPosts(t, "---\ntitle: Hello\ndate: 2025-01-01\n---\n\nContent.\n")
+// queries
 
-	req := httptest.NewRequest("GET", "/nonexistent", nil)
-	req.SetPathValue("slug", "nonexistent")
-	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+func TestQueries(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "a.md", "---\ntitle: Bravo\ndate: 2025-01-01\ntags: [go]\n---\n\nContent.\n")
+	writePost(t, dir, "b.md", "---\ntitle: alpha\ndate: 2025-02-01\ntags: [Go, rust]\n---\n\nContent.\n")
+	writePost(t, dir, "c.md", "---\ntitle: Charlie\ndate: 2025-03-01\ntags: [rust]\n---\n\nContent.\n")
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("status: got %d, want %d", w.Code, http.StatusNotFound)
+	blog, err := New(Config{ContentDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-}
 
-func TestHandler_ThemeCSS(t *testing.T) {
-	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\n---\n\nContent.\n")
+	slugs := func(posts []Post) []string {
+		var s []string
+		for _, p := range posts {
+			s = append(s, p.Slug)
+		}
+		return s
+	}
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
 
-	req := httptest.NewRequest("GET", "/_themes/default", nil)
-	req.SetPathValue("theme", "default")
-	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+	if post, ok := blog.GetPost("b"); !ok || post.Title != "alpha" {
+		t.Errorf("GetPost: got %q, %v", post.Title, ok)
+	}
+	if _, ok := blog.GetPost("missing"); ok {
+		t.Error("GetPost: expected missing post not to be found")
+	}
 
-	if w.Code != http.StatusOK {
-		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	cases := []struct {
+		name string
+		got  []Post
+		want []string
+	}{
+		{"PostsByTag", blog.PostsByTag("GO"), []string{"b", "a"}},
+		{"PostsBetween", blog.PostsBetween(date("2025-01-15"), date("2025-03-01")), []string{"b"}},
+		{"PostsBetween open", blog.PostsBetween(time.Time{}, date("2025-02-01")), []string{"a"}},
+		{"Query tag and date", blog.Query(QueryOptions{Tag: "rust", From: date("2025-02-01")}), []string{"c", "b"}},
+		{"Query oldest", blog.Query(QueryOptions{Sort: SortOldest, Limit: 2}), []string{"a", "b"}},
+		{"Query title", blog.Query(QueryOptions{Sort: SortTitle, Offset: 1}), []string{"a", "c"}},
+		{"Query offset past end", blog.Query(QueryOptions{Offset: 10}), nil},
 	}
-	if ct := w.Header().Get("Content-Type"); ct != "text/css" {
-		t.Errorf("Content-Type: got %q, want %q", ct, "text/css")
+	for _, c := range cases {
+		if got := slugs(c.got); !slices.Equal(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	posts := blog.PostsByTag("go")
+	posts[0].Tags[0] = "changed"
+	if post, _ := blog.GetPost(posts[0].Slug); post.Tags[0] == "changed" {
+		t.Error("expected returned posts not to share memory with the blog")
 	}
 }
 
-func TestHandler_ThemeCSS_InvalidTheme(t *testing.T) {
-	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\n---\n\nContent.\n")
+func TestQueries_UndatedPosts(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "dated.md", "---\ntitle: Dated\ndate: 2019-06-01\n---\n\nContent.\n")
+	writePost(t, dir, "undated.md", "---\ntitle: Undated\n---\n\nContent.\n")
+	blog, err := New(Config{ContentDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	cases := []struct {
+		name string
+		got  []Post
+		want int
+	}{
+		{"no bounds", blog.PostsBetween(time.Time{}, time.Time{}), 2},
+		{"to only", blog.PostsBetween(time.Time{}, date("2020-01-01")), 1},
+		{"from only", blog.PostsBetween(date("2019-01-01"), time.Time{}), 1},
+		{"Query to", blog.Query(QueryOptions{To: date("2020-01-01")}), 1},
+	}
+	for _, c := range cases {
+		if len(c.got) != c.want {
+			t.Errorf("%s: got %d posts, want %d", c.name, len(c.got), c.want)
+		}
+		for _, post := range c.got {
+			if c.want == 1 && post.PublishDate.IsZero() {
+				t.Errorf("%s: expected undated post to be excluded", c.name)
+			}
+		}
+	}
 
-	req := httptest.NewRequest("GET", "/_themes/invalid", nil)
-	req.SetPathValue("theme", "invalid")
 	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_api/posts?to=2020-01-01", nil))
+	var list struct {
+		Posts []struct {
+			Slug string `json:"slug"`
+		} `json:"posts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Posts) != 1 || list.Posts[0].Slug != "dated" {
+		t.Errorf("/_api/posts?to=: expected only the dated post, got %+v", list.Posts)
+	}
+}
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("status: got %d, want %d", w.Code, http.StatusNotFound)
+// tags
+
+func TestTagSlug(t *testing.T) {
+	cases := map[string]string{
+		"go":                 "go",
+		"Go":                 "go",
+		" golang ":           "golang",
+		"machine learning":   "machine-learning",
+		"Machine  Learning!": "machine-learning",
+		"C++":                "c-plus-plus",
+		"C#":                 "c-sharp",
+		"node.js":            "node-js",
+		"日本語":                "日本語",
+		"!!!":                "",
+	}
+	for tag, want := range cases {
+		if got := tagSlug(tag); got != want {
+			t.Errorf("tagSlug(%q): got %q, want %q", tag, got, want)
+		}
 	}
 }
 
-func TestHandler_Feed(t *testing.T) {
+func TestInitialize_NormalizesTags(t *testing.T) {
 	dir := t.TempDir()
-	writePost(t, dir, "hello.md", "---\ntitle: Hello World\ndate: 2025-03-01\ndescription: A test post\n---\n\nContent.\n")
+	writePost(t, dir, "one.md", "---\ntitle: One\ndate: 2025-01-02\ntags: [Go, \" golang \", Machine Learning]\n---\n\nContent.\n")
+	writePost(t, dir, "two.md", "---\ntitle: Two\ndate: 2025-01-01\ntags: [go]\n---\n\nContent.\n")
 
-	blog, err := New(Config{
-		ContentDir:  dir,
-		URLPrefix:   "/blog",
-		Theme:       "default",
-		Title:       "My Blog",
-		Description: "A test blog",
-		BaseURL:     "https://example.com",
-	})
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", TagAliases: map[string]string{"GoLang": "Go"}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	req := httptest.NewRequest("GET", "/feed.xml", nil)
+	posts := blog.GetPosts()
+	if !slices.Equal(posts[0].Tags, []string{"Go", "Machine Learning"}) {
+		t.Errorf("tags: got %q", posts[0].Tags)
+	}
+
+	want := []TagCount{{Name: "Go", Slug: "go", Count: 2}, {Name: "Machine Learning", Slug: "machine-learning", Count: 1}}
+	if got := blog.Tags(); !slices.Equal(got, want) {
+		t.Errorf("Tags(): got %v, want %v", got, want)
+	}
+
 	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_tags/machine-learning", nil))
 
-	if w.Code != http.StatusOK {
-		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	body := w.Body.String()
+	if !strings.Contains(body, "Posts tagged: Machine Learning") {
+		t.Error("expected tag label on tag page")
 	}
-	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/rss+xml") {
-		t.Errorf("Content-Type: got %q, want application/rss+xml", ct)
+	if !strings.Contains(body, `href="/blog/_tags/machine-learning"`) {
+		t.Error("expected slugified tag link")
 	}
+}
 
-	body := w.Body.String()
-	if !strings.Contains(body, "<title>My Blog</title>") {
-		t.Error("expected channel title in feed")
+// archive
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "a.md", "---\ntitle: March A\ndate: 2025-03-10\n---\n\nContent.\n")
+	writePost(t, dir, "b.md", "---\ntitle: March B\ndate: 2025-03-01\n---\n\nContent.\n")
+	writePost(t, dir, "c.md", "---\ntitle: January\ndate: 2025-01-05\n---\n\nContent.\n")
+	writePost(t, dir, "d.md", "---\ntitle: Last Year\ndate: 2024-12-31\n---\n\nContent.\n")
+	writePost(t, dir, "e.md", "---\ntitle: Undated\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if !strings.Contains(body, "<title>Hello World</title>") {
-		t.Error("expected post title in feed")
+
+	years := blog.Archive()
+	if len(years) != 2 || years[0].Year != 2025 || years[1].Year != 2024 {
+		t.Fatalf("expected 2025 then 2024, got %+v", years)
 	}
-	if !strings.Contains(body, "https://example.com/blog/hello") {
-		t.Error("expected full post URL in feed")
+	if years[0].Count != 3 || len(years[0].Months) != 2 {
+		t.Errorf("2025: got %d posts in %d months, want 3 in 2", years[0].Count, len(years[0].Months))
 	}
-	if !strings.Contains(body, "<description>A test post</description>") {
-		t.Error("expected post description in feed")
+	if m := years[0].Months[0]; m.Month != time.March || m.Count != 2 || m.Posts[0].Title != "March A" {
+		t.Errorf("unexpected first month: %+v", m)
+	}
+
+	cases := []struct {
+		path     string
+		status   int
+		contains []string
+		excludes []string
+	}{
+		{"/_archive/", http.StatusOK, []string{`href="/blog/_archive/2025/03"`, `href="/blog/_archive/2024"`, "December"}, nil},
+		{"/_archive/2025", http.StatusOK, []string{"Posts from 2025", "March A", "January"}, []string{"Last Year"}},
+		{"/_archive/2025/03", http.StatusOK, []string{"Posts from March 2025", "March B"}, []string{"January"}},
+		{"/_archive/2025/02", http.StatusNotFound, nil, nil},
+		{"/_archive/2025/13", http.StatusNotFound, nil, nil},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+
+		if w.Code != c.status {
+			t.Errorf("GET %s: status got %d, want %d", c.path, w.Code, c.status)
+			continue
+		}
+		body := w.Body.String()
+		for _, s := range c.contains {
+			if !strings.Contains(body, s) {
+				t.Errorf("GET %s: expected %q in response", c.path, s)
+			}
+		}
+		for _, s := range c.excludes {
+			if strings.Contains(body, s) {
+				t.Errorf("GET %s: expected %q to be absent", c.path, s)
+			}
+		}
 	}
 }
 
-func TestHandler_Feed_NoBaseURL(t *testing.T) {
-	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\n---\n\nContent.\n")
+// search
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "goroutines.md", "---\ntitle: Running goroutines\ndate: 2025-01-01\ntags: [go]\n---\n\nHow the scheduler runs goroutines on threads.\n")
+	writePost(t, dir, "rust.md", "---\ntitle: Rust threads\ndate: 2025-01-02\ntags: [rust]\n---\n\nSpawning OS threads & sharing data `<safely>`.\n")
+	writePost(t, dir, "other.md", "---\ntitle: Something else\ndate: 2025-01-03\ndescription: Mentions goroutine once\n---\n\nUnrelated.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results := blog.Search("Goroutine")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].Post.Slug != "goroutines" {
+		t.Errorf("expected title match to rank first, got %q", results[0].Post.Slug)
+	}
+	if !strings.Contains(string(results[0].Snippet), "runs <mark>goroutines</mark> on threads") {
+		t.Errorf("unexpected snippet: %q", results[0].Snippet)
+	}
+
+	if got := blog.Search("threads sharing"); len(got) != 1 || got[0].Post.Slug != "rust" {
+		t.Errorf("expected every query term to be required, got %d results", len(got))
+	}
+	if got := blog.Search("the"); len(got) != 0 {
+		t.Errorf("expected stop words to be ignored, got %d results", len(got))
+	}
 
-	req := httptest.NewRequest("GET", "/feed.xml", nil)
 	w := httptest.NewRecorder()
-	blog.Handler().ServeHTTP(w, req)
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_search?q=threads", nil))
 
 	if w.Code != http.StatusOK {
 		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
 	}
-	if !strings.Contains(w.Body.String(), "/blog/hello") {
-		t.Error("expected relative post URL in feed when BaseURL is empty")
+	body := w.Body.String()
+	if !strings.Contains(body, `value="threads"`) || !strings.Contains(body, "Rust threads") {
+		t.Error("expected query and results in search page")
+	}
+	if !strings.Contains(body, "&amp; sharing data &lt;safely&gt;") {
+		t.Error("expected snippet text to be escaped")
 	}
 }
 
-// helpers
+func TestHandler_SearchIndex(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello Gophers\ndate: 2025-01-01\ntags: [Go]\n---\n\nRunning the scheduler.\n")
 
-func writeTempPost(t *testing.T, content string) string {
-	t.Helper()
-	f, err := os.CreateTemp(t.TempDir(), "*.md")
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", EnableSearch: true})
 	if err != nil {
-		t.Fatalf("creating temp file: %v", err)
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_search/index.json", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type: got %q, want application/json", ct)
+	}
+	var docs []struct {
+		URL   string   `json:"url"`
+		Slug  string   `json:"slug"`
+		Terms []string `json:"terms"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &docs); err != nil {
+		t.Fatalf("decoding index: %v", err)
 	}
-	if _, err := f.WriteString(content); err != nil {
-		t.Fatalf("writing temp file: %v", err)
+	if len(docs) != 1 || docs[0].URL != "/blog/hello" {
+		t.Fatalf("unexpected index: %+v", docs)
 	}
-	f.Close()
-	return f.Name()
+	if want := []string{"go", "gopher", "hello", "run", "scheduler"}; !slices.Equal(docs[0].Terms, want) {
+		t.Errorf("terms: got %q, want %q", docs[0].Terms, want)
+	}
+
+	for _, path := range []string{"/", "/hello"} {
+		w := httptest.NewRecorder()
+		blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if !strings.Contains(w.Body.String(), `src="/blog/_search/search.js"`) {
+			t.Errorf("GET %s: expected search widget script", path)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_search/search.js", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/javascript") {
+		t.Errorf("expected search script, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+// api
+
+func TestAPI(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "one.md", "---\ntitle: One\ndate: 2025-01-01\ntags: [Go]\n---\n\n# Heading\n\nFirst.\n")
+	writePost(t, dir, "two.md", "---\ntitle: Two\ndate: 2025-02-01\ntags: [go]\n---\n\nSecond.\n")
+	writePost(t, dir, "three.md", "---\ntitle: Three\ndate: 2025-03-01\n---\n\nThird.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	get := func(path string, status int, v any) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != status {
+			t.Errorf("GET %s: status got %d, want %d", path, w.Code, status)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("GET %s: Content-Type got %q, want application/json", path, ct)
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Errorf("GET %s: decoding response: %v", path, err)
+		}
+	}
+
+	type post struct {
+		Slug     string   `json:"slug"`
+		Tags     []string `json:"tags"`
+		HTML     string   `json:"html"`
+		Markdown string   `json:"markdown"`
+	}
+	var list struct {
+		Posts      []post `json:"posts"`
+		Total      int    `json:"total"`
+		TotalPages int    `json:"total_pages"`
+	}
+
+	get("/_api/posts?per_page=2&page=2", http.StatusOK, &list)
+	if list.Total != 3 || list.TotalPages != 2 || len(list.Posts) != 1 || list.Posts[0].Slug != "one" {
+		t.Errorf("unexpected page: %+v", list)
+	}
+
+	get("/_api/posts?tag=go&from=2025-01-15&to=2025-02-01", http.StatusOK, &list)
+	if list.Total != 1 || list.Posts[0].Slug != "two" {
+		t.Errorf("expected tag and date filters to apply, got %+v", list)
+	}
+
+	var single post
+	get("/_api/posts/one?raw=1", http.StatusOK, &single)
+	if !strings.Contains(single.HTML, `<h1 id="heading">Heading</h1>`) || single.Markdown != "# Heading\n\nFirst." {
+		t.Errorf("unexpected post: %+v", single)
+	}
+
+	var tags []struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	get("/_api/tags", http.StatusOK, &tags)
+	if len(tags) != 1 || tags[0].Count != 2 {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	get("/_api/posts/missing", http.StatusNotFound, &apiErr)
+	get("/_api/posts?from=yesterday", http.StatusBadRequest, &apiErr)
+	if !strings.Contains(apiErr.Error, "from") {
+		t.Errorf("expected error message, got %q", apiErr.Error)
+	}
+	get("/_api/unknown", http.StatusNotFound, &apiErr)
+}
+
+// themes
+
+func TestValidateTheme(t *testing.T) {
+	valid := []string{"default", "dark", "light", "rosepine"}
+	for _, theme := range valid {
+		if !validateTheme(theme) {
+			t.Errorf("expected %q to be valid", theme)
+		}
+	}
+	if validateTheme("invalid") {
+		t.Error("expected \"invalid\" to be invalid")
+	}
+}
+
+func TestDefaultSyntaxTheme(t *testing.T) {
+	cases := []struct {
+		theme  string
+		syntax string
+	}{
+		{"rosepine", "rose-pine"},
+		{"dark", "github-dark"},
+		{"light", "github"},
+		{"default", "github"},
+		{"unknown", "github"},
+	}
+	for _, c := range cases {
+		got := defaultSyntaxTheme(c.theme)
+		if got != c.syntax {
+			t.Errorf("defaultSyntaxTheme(%q): got %q, want %q", c.theme, got, c.syntax)
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\ndate: 2025-01-01\ntags: [go]\n---\n\n```go\nfmt.Println(1)\n```\n")
+	blog, err := New(Config{ContentDir: dir, Offline: true})
+	if err != nil {
+		t.Fatalf("expected default SyntaxTheme to work offline, got %v", err)
+	}
+	handler := blog.Handler()
+
+	for _, path := range []string{"/hello", "/", "/_tags/", "/_archive/"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		body := w.Body.String()
+		if strings.Contains(body, "https://") {
+			t.Errorf("%s: expected no third-party URLs in offline mode", path)
+		}
+		if !strings.Contains(body, `href="/blog/_assets/fonts/dejavu-sans-mono.css"`) {
+			t.Errorf("%s: expected bundled font stylesheet", path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `href="/blog/_themes/syntax/github.css"`) || !strings.Contains(body, `class="chroma"`) {
+		t.Error("expected code to be highlighted server-side in offline mode")
+	}
+	if strings.Contains(body, "highlight.min.js") {
+		t.Error("expected highlight.js not to be loaded in offline mode")
+	}
+
+	// Every asset the font stylesheet references must be bundled.
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_assets/fonts/dejavu-sans-mono.css", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected bundled font stylesheet, got status %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/css; charset=utf-8" {
+		t.Errorf("Content-Type: got %q", ct)
+	}
+	fonts := regexp.MustCompile(`url\("([^"]+)"\)`).FindAllStringSubmatch(w.Body.String(), -1)
+	if len(fonts) == 0 {
+		t.Fatal("expected font stylesheet to reference font files")
+	}
+	for _, m := range fonts {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/_assets/fonts/"+m[1], nil))
+		if w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("%s: expected bundled font, got status %d", m[1], w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "font/ttf" {
+			t.Errorf("%s: Content-Type: got %q", m[1], ct)
+		}
+	}
+
+	for _, path := range []string{"/_assets/missing.css", "/_assets/README.md", "/_assets/fonts/LICENSE"} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status 404, got %d", path, w.Code)
+		}
+	}
+
+	for _, theme := range []string{"dark", "rosepine"} {
+		if _, err := New(Config{ContentDir: dir, Offline: true, Theme: theme}); err != nil {
+			t.Errorf("expected default SyntaxTheme for %s to work offline, got %v", theme, err)
+		}
+	}
+	if _, err := New(Config{ContentDir: dir, Offline: true, SyntaxTheme: "monokai-sublime"}); err != nil {
+		t.Errorf("expected aliased SyntaxTheme to work offline, got %v", err)
+	}
+	if _, err := New(Config{ContentDir: dir, Offline: true, SyntaxTheme: "no-such-theme"}); err == nil || !strings.Contains(err.Error(), "no-such-theme") {
+		t.Errorf("expected error for syntax theme without a server-side style, got %v", err)
+	}
+}
+
+func TestServerHighlight(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\n---\n\n```go\nfunc main() {}\n```\n")
+	blog, err := New(Config{ContentDir: dir, Theme: "dark", ServerHighlight: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := blog.Handler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `<pre class="chroma">`) || !strings.Contains(body, `<span class="kd">func</span>`) {
+		t.Errorf("expected code block to be highlighted with classes, got:\n%s", body)
+	}
+	if strings.Contains(body, "highlight.min.js") {
+		t.Error("expected highlight.js not to be loaded")
+	}
+	if !strings.Contains(body, `href="/blog/_themes/syntax/github-dark.css"`) {
+		t.Error("expected generated syntax stylesheet to be linked")
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_themes/syntax/github-dark.css", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), ".chroma .kd") {
+		t.Errorf("expected generated syntax CSS, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/_themes/syntax/nonexistent.css", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for unknown style, got %d", w.Code)
+	}
+}
+
+func TestChromaStyle(t *testing.T) {
+	cases := []struct {
+		theme, syntax string
+		want          string
+	}{
+		{"default", "monokai", "monokai"},
+		{"default", "Rose-Pine", "rose-pine"},
+		{"default", "tokyo-night-dark", "tokyonight-night"},
+		{"dark", "no-such-style", "github-dark"},
+		{"rosepine", "", "rose-pine"},
+	}
+	for _, c := range cases {
+		cfg := Config{Theme: c.theme, SyntaxTheme: c.syntax}
+		cfg.setDefaults()
+		if got := chromaStyle(cfg); got != c.want {
+			t.Errorf("chromaStyle(%q, %q): got %q, want %q", c.theme, c.syntax, got, c.want)
+		}
+	}
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	overrides := fstest.MapFS{
+		"list.html":              {Data: []byte(`{{template "head" .}}<p>{{len .Posts}} posts</p>{{template "greeting" .}}`)},
+		"partials/header.html":   {Data: []byte(`<nav class="site-nav">{{.BlogTitle}}</nav>`)},
+		"partials/footer.html":   {Data: []byte(`<script src="/analytics.js"></script>`)},
+		"partials/greeting.html": {Data: []byte(`<p>Hi from {{.BlogTitle}}</p>`)},
+	}
+	blog, err := New(Config{ContentDir: dir, Title: "My Site", TemplatesFS: overrides})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := blog.Handler()
+
+	get := func(path string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Body.String()
+	}
+
+	list := get("/")
+	for _, want := range []string{`<link rel="stylesheet" href="/blog/_themes/default.css">`, "<p>1 posts</p>", "<p>Hi from My Site</p>"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("list: expected overridden template to render %s, got:\n%s", want, list)
+		}
+	}
+	if strings.Contains(list, "post-list") {
+		t.Error("list: expected built-in template to be replaced")
+	}
+
+	post := get("/hello")
+	for _, want := range []string{`<nav class="site-nav">My Site</nav>`, `<script src="/analytics.js"></script>`, "<h1>Hello</h1>"} {
+		if !strings.Contains(post, want) {
+			t.Errorf("post: expected built-in template with overridden partials to render %s", want)
+		}
+	}
+
+	templateDir := t.TempDir()
+	writePost(t, templateDir, "post.html", `custom post: {{.Title}}`)
+	blog, err = New(Config{ContentDir: dir, TemplateDir: templateDir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+	if got := w.Body.String(); got != "custom post: Hello" {
+		t.Errorf("TemplateDir: got %q", got)
+	}
+
+	broken := fstest.MapFS{"partials/header.html": {Data: []byte(`{{if}}`)}}
+	if _, err := New(Config{ContentDir: dir, TemplatesFS: broken}); err == nil || !strings.Contains(err.Error(), "partials/header.html") {
+		t.Errorf("expected parse error naming the broken template, got %v", err)
+	}
+}
+
+//handlers
+
+func TestHandler_ListPosts(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Test Post\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), "Test Post") {
+		t.Error("expected post title in response body")
+	}
+}
+
+func TestHandler_TaggedPosts(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "tagged.md", "---\ntitle: Tagged Post\ndate: 2025-01-01\ntags: [go, test]\n---\n\nContent.\n")
+	writePost(t, dir, "other.md", "---\ntitle: Other Post\ndate: 2025-01-02\ntags: [rust]\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", Theme: "default"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/_tags/go", nil)
+	req.SetPathValue("tag", "go")
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Tagged Post") {
+		t.Error("expected tagged post in response")
+	}
+	if strings.Contains(body, "Other Post") {
+		t.Error("expected other post to be excluded")
+	}
+	if !strings.Contains(body, "Posts tagged: go") {
+		t.Error("expected tag title in response")
+	}
+}
+
+func TestHandler_Pagination(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "one.md", "---\ntitle: Post One\ndate: 2025-01-01\ntags: [go]\n---\n\nContent.\n")
+	writePost(t, dir, "two.md", "---\ntitle: Post Two\ndate: 2025-01-02\ntags: [go]\n---\n\nContent.\n")
+	writePost(t, dir, "three.md", "---\ntitle: Post Three\ndate: 2025-01-03\ntags: [go]\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", PostsPerPage: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		path     string
+		status   int
+		contains []string
+		excludes []string
+	}{
+		{"/", http.StatusOK, []string{"Post Three", "Post Two", `href="/blog/page/2"`, "Page 1 of 2"}, []string{"Post One", `rel="prev"`}},
+		{"/page/2", http.StatusOK, []string{"Post One", `href="/blog/"`, "Page 2 of 2"}, []string{"Post Two", `rel="next"`}},
+		{"/page/3", http.StatusNotFound, nil, nil},
+		{"/page/zero", http.StatusNotFound, nil, nil},
+		{"/_tags/go/page/2", http.StatusOK, []string{"Post One", `href="/blog/_tags/go"`}, []string{"Post Three"}},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+
+		if w.Code != c.status {
+			t.Errorf("GET %s: status got %d, want %d", c.path, w.Code, c.status)
+			continue
+		}
+		body := w.Body.String()
+		for _, s := range c.contains {
+			if !strings.Contains(body, s) {
+				t.Errorf("GET %s: expected %q in response", c.path, s)
+			}
+		}
+		for _, s := range c.excludes {
+			if strings.Contains(body, s) {
+				t.Errorf("GET %s: expected %q to be absent", c.path, s)
+			}
+		}
+	}
+}
+
+func TestHandler_TagIndex(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "one.md", "---\ntitle: One\ndate: 2025-01-01\ntags: [rust, go]\n---\n\nContent.\n")
+	writePost(t, dir, "two.md", "---\ntitle: Two\ndate: 2025-01-02\ntags: [go]\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tags := blog.Tags()
+	want := []TagCount{{Name: "go", Slug: "go", Count: 2}, {Name: "rust", Slug: "rust", Count: 1}}
+	if !slices.Equal(tags, want) {
+		t.Errorf("Tags(): got %v, want %v", tags, want)
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_tags/?sort=count", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	goIdx := strings.Index(body, `href="/blog/_tags/go"`)
+	rustIdx := strings.Index(body, `href="/blog/_tags/rust"`)
+	if goIdx < 0 || rustIdx < 0 || goIdx > rustIdx {
+		t.Error("expected tags sorted by count with links to tag pages")
+	}
+}
+
+func TestHandler_SinglePost(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Hello\ndate: 2025-01-01\n---\n\nBody content.\n")
+
+	req := httptest.NewRequest("GET", "/hello", nil)
+	req.SetPathValue("slug", "hello")
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), "Body content") {
+		t.Error("expected post content in response body")
+	}
+}
+
+func TestHandler_SinglePost_NotFound(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Hello\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	req := httptest.NewRequest("GET", "/nonexistent", nil)
+	req.SetPathValue("slug", "nonexistent")
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_ThemeCSS(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	req := httptest.NewRequest("GET", "/_themes/default", nil)
+	req.SetPathValue("theme", "default")
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/css" {
+		t.Errorf("Content-Type: got %q, want %q", ct, "text/css")
+	}
+}
+
+func TestHandler_ThemeCSS_InvalidTheme(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	req := httptest.NewRequest("GET", "/_themes/invalid", nil)
+	req.SetPathValue("theme", "invalid")
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_Feed(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello World\ndate: 2025-03-01\ndescription: A test post\n---\n\nContent.\n")
+
+	blog, err := New(Config{
+		ContentDir:  dir,
+		URLPrefix:   "/blog",
+		Theme:       "default",
+		Title:       "My Blog",
+		Description: "A test blog",
+		BaseURL:     "https://example.com",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/feed.xml", nil)
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/rss+xml") {
+		t.Errorf("Content-Type: got %q, want application/rss+xml", ct)
+	}
+
+	body := w.Body.String()
+	if !strings.Contains(body, "<title>My Blog</title>") {
+		t.Error("expected channel title in feed")
+	}
+	if !strings.Contains(body, "<title>Hello World</title>") {
+		t.Error("expected post title in feed")
+	}
+	if !strings.Contains(body, "https://example.com/blog/hello") {
+		t.Error("expected full post URL in feed")
+	}
+	if !strings.Contains(body, "<description>A test post</description>") {
+		t.Error("expected post description in feed")
+	}
+}
+
+func TestHandler_Feed_NoBaseURL(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	req := httptest.NewRequest("GET", "/feed.xml", nil)
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), "/blog/hello") {
+		t.Error("expected relative post URL in feed when BaseURL is empty")
+	}
+}
+
+// watch
+
+func TestWatch_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "first.md", "---\ntitle: First\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, ReloadInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go blog.Watch(ctx)
+
+	writePost(t, dir, "second.md", "---\ntitle: Second\ndate: 2025-01-02\n---\n\nContent.\n")
+	waitFor(t, func() bool { return len(blog.GetPosts()) == 2 })
+
+	if err := os.Remove(filepath.Join(dir, "first.md")); err != nil {
+		t.Fatalf("removing post: %v", err)
+	}
+	waitFor(t, func() bool { return len(blog.GetPosts()) == 1 })
+
+	if got := blog.GetPosts()[0].Title; got != "Second" {
+		t.Errorf("got %q, want %q", got, "Second")
+	}
+}
+
+func TestWatch_KeepsLastGoodPostsOnError(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "good.md", "---\ntitle: Good\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	var logs syncBuffer
+	blog, err := New(Config{
+		ContentDir:     dir,
+		Watch:          true,
+		ReloadInterval: 10 * time.Millisecond,
+		ErrorLog:       log.New(&logs, "", 0),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer blog.Close()
+
+	writePost(t, dir, "bad.md", "no frontmatter\n")
+	waitFor(t, func() bool { return strings.Contains(logs.String(), "reload failed") })
+
+	posts := blog.GetPosts()
+	if len(posts) != 1 || posts[0].Title != "Good" {
+		t.Errorf("expected previous posts to be kept, got %d posts", len(posts))
+	}
+}
+
+// sitemap
+
+func TestHandler_Sitemap(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\ndate: 2025-03-01\nupdated: 2025-05-01\ntags: [go]\n---\n\nContent.\n")
+	writePost(t, dir, "older.md", "---\ntitle: Older\ndate: 2025-01-01\ntags: [go, rust]\n---\n\nContent.\n")
+	writePost(t, dir, "draft.md", "---\ntitle: Draft\ndate: 2025-06-01\ndraft: true\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := blog.SitemapEntries()
+	if len(entries) != 5 {
+		t.Fatalf("entries: got %d, want 5 (index, 2 posts, 2 tags)", len(entries))
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/sitemap.xml", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		"<loc>https://example.com/blog/</loc>\n    <lastmod>2025-05-01</lastmod>",
+		"<loc>https://example.com/blog/hello</loc>\n    <lastmod>2025-05-01</lastmod>",
+		"<loc>https://example.com/blog/older</loc>\n    <lastmod>2025-01-01</lastmod>",
+		"<loc>https://example.com/blog/_tags/go</loc>\n    <lastmod>2025-05-01</lastmod>",
+		"<loc>https://example.com/blog/_tags/rust</loc>\n    <lastmod>2025-01-01</lastmod>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in sitemap", want)
+		}
+	}
+	if strings.Contains(body, "draft") {
+		t.Error("expected drafts to be excluded from sitemap")
+	}
+}
+
+func TestRobotsHandler(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	robots := func(blog *Blog, req *http.Request) string {
+		w := httptest.NewRecorder()
+		blog.RobotsHandler()(w, req)
+		return w.Body.String()
+	}
+
+	req := httptest.NewRequest("GET", "http://example.com/robots.txt", nil)
+	if body := robots(blog, req); !strings.Contains(body, "Sitemap: http://example.com/blog/sitemap.xml\n") {
+		t.Errorf("expected absolute sitemap URL from the request, got %q", body)
+	}
+
+	req = httptest.NewRequest("GET", "http://example.com/robots.txt", nil)
+	req.Header.Set("X-Forwarded-Proto", "javascript")
+	if body := robots(blog, req); !strings.Contains(body, "Sitemap: http://example.com/blog/sitemap.xml\n") {
+		t.Errorf("expected X-Forwarded-Proto to be ignored, got %q", body)
+	}
+
+	dir := t.TempDir()
+	writePost(t, dir, "post.md", "---\ntitle: Post\n---\n\nContent.\n")
+	withBase, err := New(Config{ContentDir: dir, BaseURL: "https://blog.example.org/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body := robots(withBase, httptest.NewRequest("GET", "http://internal:8080/robots.txt", nil)); !strings.Contains(body, "Sitemap: https://blog.example.org/blog/sitemap.xml\n") {
+		t.Errorf("expected sitemap URL from BaseURL, got %q", body)
+	}
+}
+
+// standalone handler
+
+func TestPostHandlerFS(t *testing.T) {
+	fsys, name := memPost("---\ntitle: Changelog\n---\n\nAll notable changes.\n")
+
+	w := httptest.NewRecorder()
+	PostHandlerFS(fsys, name, ThemeDark)(w, httptest.NewRequest("GET", "/changelog", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), "All notable changes") {
+		t.Error("expected post content in response body")
+	}
+}
+
+func TestHandler_Feed_FullContentAndLimit(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\ndate: 2025-03-01\n---\n\n![diagram](img/diagram.png) see [about](/about) or [docs](https://go.dev).\n")
+	writePost(t, dir, "older.md", "---\ntitle: Older\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	blog, err := New(Config{
+		ContentDir:      dir,
+		URLPrefix:       "/blog",
+		BaseURL:         "https://example.com",
+		FeedFullContent: true,
+		FeedLimit:       1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/feed.xml", nil))
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`xmlns:content="http://purl.org/rss/1.0/modules/content/"`,
+		"<content:encoded>",
+		`src=&#34;https://example.com/blog/img/diagram.png&#34;`,
+		`href=&#34;https://example.com/about&#34;`,
+		`href=&#34;https://go.dev&#34;`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in feed", want)
+		}
+	}
+	if strings.Contains(body, "<title>Older</title>") {
+		t.Error("expected FeedLimit to exclude older post")
+	}
+}
+
+func TestHandler_TagFeed(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "tagged.md", "---\ntitle: Tagged Post\ndate: 2025-01-01\ntags: [go]\n---\n\nContent.\n")
+	writePost(t, dir, "other.md", "---\ntitle: Other Post\ndate: 2025-01-02\ntags: [rust]\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", Title: "My Blog", BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_tags/go/feed.xml", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "<title>My Blog — Posts tagged: go</title>") {
+		t.Error("expected tag in channel title")
+	}
+	if !strings.Contains(body, "<link>https://example.com/blog/_tags/go</link>") {
+		t.Error("expected tag page as channel link")
+	}
+	if !strings.Contains(body, "Tagged Post") || strings.Contains(body, "Other Post") {
+		t.Error("expected only posts with the tag in feed")
+	}
+
+	w = httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/_tags/go", nil))
+	if !strings.Contains(w.Body.String(), `href="/blog/_tags/go/feed.xml"`) {
+		t.Error("expected tag page to advertise the tag feed")
+	}
+}
+
+func TestHandler_FeedLinks(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Post\ndate: 2025-01-01\ntags: [go]\n---\n\nContent.\n")
+	handler := blog.Handler()
+
+	for _, path := range []string{"/", "/hello", "/_tags/", "/_tags/go", "/_archive/", "/_archive/2025", "/_search?q=content"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		body := w.Body.String()
+		for _, want := range []string{
+			`<link rel="alternate" type="application/rss+xml" title="Blog" href="/blog/feed.xml">`,
+			`<link rel="alternate" type="application/atom+xml" title="Blog" href="/blog/atom.xml">`,
+			`<link rel="alternate" type="application/feed+json" title="Blog" href="/blog/feed.json">`,
+		} {
+			if strings.Count(body, want) != 1 {
+				t.Errorf("%s: expected one %s", path, want)
+			}
+		}
+	}
+}
+
+func TestHandler_AtomFeed(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello World\ndate: 2025-03-01\nupdated: 2025-04-01\nauthor: Jo\ndescription: A test post\ntags: [go]\n---\n\nFull body.\n")
+
+	blog, err := New(Config{
+		ContentDir:      dir,
+		URLPrefix:       "/blog",
+		Title:           "My Blog",
+		BaseURL:         "https://example.com",
+		FeedFullContent: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/atom.xml", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+		t.Errorf("Content-Type: got %q, want application/atom+xml", ct)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		"<id>https://example.com/blog/hello</id>",
+		"<published>2025-03-01T00:00:00Z</published>",
+		"<updated>2025-04-01T00:00:00Z</updated>",
+		"<name>Jo</name>",
+		"<name>My Blog</name>",
+		`<summary type="text">A test post</summary>`,
+		`<content type="html">&lt;p&gt;Full body.&lt;/p&gt;`,
+		`<category term="go"></category>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in feed", want)
+		}
+	}
+}
+
+func TestHandler_AtomFeedWithoutBaseURL(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\ndate: 2025-03-01\n---\n\nContent.\n")
+	writePost(t, dir, "undated.md", "---\ntitle: Undated\n---\n\nContent.\n")
+	modTime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(dir, "undated.md"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	blog, err := New(Config{ContentDir: dir, Title: "My Blog"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/atom.xml", nil))
+
+	var feed struct {
+		ID      string `xml:"id"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			Title     string `xml:"title"`
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid feed: %v", err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+	}
+
+	ids := map[string]bool{feed.ID: true}
+	for _, e := range feed.Entries {
+		ids[e.ID] = true
+	}
+	for id := range ids {
+		if !strings.HasPrefix(id, "urn:uuid:") {
+			t.Errorf("expected an absolute id without BaseURL, got %q", id)
+		}
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected distinct ids for the feed and each entry, got %v", ids)
+	}
+	if feed.Entries[0].ID != nameUUID("My Blog/blog/hello") {
+		t.Errorf("expected a stable id, got %q", feed.Entries[0].ID)
+	}
+
+	undated := feed.Entries[1]
+	if undated.Title != "Undated" {
+		t.Fatalf("expected undated post last, got %q", undated.Title)
+	}
+	if strings.Contains(w.Body.String(), "0001-01-01") {
+		t.Error("expected no zero dates in feed")
+	}
+	if undated.Published != "" {
+		t.Errorf("expected no <published> for an undated post, got %q", undated.Published)
+	}
+	if undated.Updated != "2025-05-01T12:00:00Z" {
+		t.Errorf("expected <updated> from the file's modification time, got %q", undated.Updated)
+	}
+	if feed.Updated != "2025-05-01T12:00:00Z" {
+		t.Errorf("feed <updated>: got %q", feed.Updated)
+	}
+}
+
+func TestHandler_JSONFeed(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello World\ndate: 2025-03-01\ndescription: A test post\ntags: [go]\n---\n\nFull body.\n")
+	writePost(t, dir, "older.md", "---\ntitle: Older\ndate: 2025-01-01\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, URLPrefix: "/blog", Title: "My Blog", BaseURL: "https://example.com/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/feed.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/feed+json") {
+		t.Errorf("Content-Type: got %q, want application/feed+json", ct)
+	}
+
+	var feed struct {
+		Version     string `json:"version"`
+		Title       string `json:"title"`
+		HomePageURL string `json:"home_page_url"`
+		FeedURL     string `json:"feed_url"`
+		Items       []struct {
+			ID            string   `json:"id"`
+			URL           string   `json:"url"`
+			ContentHTML   string   `json:"content_html"`
+			Summary       string   `json:"summary"`
+			DatePublished string   `json:"date_published"`
+			Tags          []string `json:"tags"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("decoding feed: %v", err)
+	}
+
+	if feed.Version != "https://jsonfeed.org/version/1.1" {
+		t.Errorf("version: got %q", feed.Version)
+	}
+	if feed.FeedURL != "https://example.com/blog/feed.json" {
+		t.Errorf("feed_url: got %q", feed.FeedURL)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("items: got %d, want 2", len(feed.Items))
+	}
+	item := feed.Items[0]
+	if item.URL != "https://example.com/blog/hello" || item.ID != item.URL {
+		t.Errorf("url/id: got %q/%q", item.URL, item.ID)
+	}
+	if !strings.Contains(item.ContentHTML, "Full body.") {
+		t.Errorf("content_html: got %q", item.ContentHTML)
+	}
+	if item.Summary != "A test post" || item.DatePublished != "2025-03-01T00:00:00Z" || len(item.Tags) != 1 {
+		t.Errorf("unexpected item metadata: %+v", item)
+	}
+}
+
+// page cache
+
+func TestHandler_PageCacheInvalidatedOnReload(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: First Title\ndate: 2025-01-01\n---\n\nContent.\n")
+	blog, err := New(Config{ContentDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := blog.Handler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	for _, path := range []string{"/hello", "/", "/_tags/", "/_archive/"} {
+		first, second := get(path), get(path)
+		if first.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", path, first.Code)
+		}
+		if etag := first.Header().Get("ETag"); etag == "" || etag != second.Header().Get("ETag") {
+			t.Errorf("%s: expected a stable ETag, got %q and %q", path, etag, second.Header().Get("ETag"))
+		}
+		if first.Body.String() != second.Body.String() {
+			t.Errorf("%s: expected cached page to match the rendered one", path)
+		}
+	}
+
+	before := get("/hello").Header().Get("ETag")
+	writePost(t, dir, "hello.md", "---\ntitle: Second Title\ndate: 2025-01-01\n---\n\nContent.\n")
+	if err := blog.Initialize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := get("/hello")
+	if !strings.Contains(w.Body.String(), "Second Title") {
+		t.Error("expected reload to invalidate the cached post page")
+	}
+	if w.Header().Get("ETag") == before {
+		t.Error("expected ETag to change with the content")
+	}
+	if !strings.Contains(get("/").Body.String(), "Second Title") {
+		t.Error("expected reload to invalidate the cached list page")
+	}
+}
+
+func TestPageCache_MaxBytes(t *testing.T) {
+	c := newPageCache(10, nil)
+	renders := 0
+	render := func(key, body string) {
+		c.render(key, htmlContentType, time.Time{}, func() (string, error) {
+			renders++
+			return body, nil
+		})
+	}
+
+	render("/a", "aaaa")
+	render("/b", "bbbb")
+	render("/a", "aaaa") // hit; /a is now the most recently used
+	render("/c", "cccc") // evicts /b
+	if renders != 3 {
+		t.Errorf("expected 3 renders, got %d", renders)
+	}
+	if _, ok := c.get("/b"); ok {
+		t.Error("expected least recently used page to be evicted")
+	}
+	if _, ok := c.get("/a"); !ok {
+		t.Error("expected recently used page to stay cached")
+	}
+	if c.size > 10 {
+		t.Errorf("expected cache size within bound, got %d", c.size)
+	}
+
+	render("/big", "this page is too big to cache")
+	if _, ok := c.get("/big"); ok {
+		t.Error("expected page larger than the bound not to be cached")
+	}
+
+	if newPageCache(-1, nil) != nil {
+		t.Error("expected negative bound to disable the cache")
+	}
+}
+
+// conditional requests
+
+func TestHandler_ConditionalGET(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\ndate: 2025-01-01\n---\n\nContent.\n")
+	modTime := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(dir, "hello.md"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	blog, err := New(Config{
+		ContentDir:   dir,
+		CacheControl: CachePolicy{Feeds: "public, max-age=600"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := blog.Handler()
+	published := blog.snapshot().modTime
+	if !published.After(modTime) {
+		t.Fatalf("expected pages listing posts to be stamped with the load time, got %v", published)
+	}
+
+	cases := []struct {
+		path         string
+		lastModified time.Time
+		cacheControl string
+	}{
+		{"/hello", modTime, "no-cache"},
+		{"/", published, "no-cache"},
+		{"/feed.xml", published, "public, max-age=600"},
+		{"/atom.xml", published, "public, max-age=600"},
+		{"/_api/posts/hello", modTime, "no-cache"},
+		{"/_themes/default.css", buildTime, "public, max-age=86400"},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", c.path, w.Code)
+		}
+		etag := w.Header().Get("ETag")
+		if !strings.HasPrefix(etag, `"`) {
+			t.Errorf("%s: expected a strong ETag, got %q", c.path, etag)
+		}
+		if got, want := w.Header().Get("Last-Modified"), c.lastModified.UTC().Format(http.TimeFormat); got != want {
+			t.Errorf("%s: Last-Modified: got %q, want %q", c.path, got, want)
+		}
+		if got := w.Header().Get("Cache-Control"); got != c.cacheControl {
+			t.Errorf("%s: Cache-Control: got %q, want %q", c.path, got, c.cacheControl)
+		}
+
+		req := httptest.NewRequest("GET", c.path, nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("%s: If-None-Match: expected empty 304, got %d", c.path, w.Code)
+		}
+
+		req = httptest.NewRequest("GET", c.path, nil)
+		req.Header.Set("If-Modified-Since", c.lastModified.UTC().Format(http.TimeFormat))
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("%s: If-Modified-Since: expected 304, got %d", c.path, w.Code)
+		}
+
+		req = httptest.NewRequest("GET", c.path, nil)
+		req.Header.Set("If-None-Match", `"stale"`)
+		req.Header.Set("If-Modified-Since", c.lastModified.UTC().Format(http.TimeFormat))
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected a stale ETag to take precedence over If-Modified-Since, got %d", c.path, w.Code)
+		}
+	}
+}
+
+func TestHandler_ConditionalGETAfterReload(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "old.md", "---\ntitle: Old\ndate: 2025-01-01\n---\n\nOld.\n")
+	writePost(t, dir, "new.md", "---\ntitle: New\ndate: 2025-02-01\n---\n\nNew.\n")
+	blog, err := New(Config{ContentDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := blog.Handler()
+
+	lastModified := func(path string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Header().Get("Last-Modified")
+	}
+	before := lastModified("/")
+
+	// reloading unchanged content keeps the previous date
+	if err := blog.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if got := lastModified("/"); got != before {
+		t.Errorf("expected Last-Modified to be unchanged, got %q, want %q", got, before)
+	}
+
+	// deleting the newest post changes the index without changing any
+	// remaining post's modification time
+	if err := os.Remove(filepath.Join(dir, "new.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := blog.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/", "/feed.xml", "/sitemap.xml", "/_api/posts"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("If-Modified-Since", before)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200 after a post was removed, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestHandler_ConditionalGETWithoutModTime(t *testing.T) {
+	src := &memSource{docs: []Document{
+		{Path: "hello.md", Content: []byte("---\ntitle: Hello\ndate: 2025-01-01\n---\n\nFirst draft.\n")},
+	}}
+	blog, err := New(Config{Source: src})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := blog.Handler()
+
+	lastModified := map[string]string{}
+	for _, path := range []string{"/hello", "/_api/posts/hello"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		lastModified[path] = w.Header().Get("Last-Modified")
+	}
+
+	// editing the body leaves the frontmatter dates, and so lastModified, alone
+	src.set(Document{Path: "hello.md", Content: []byte("---\ntitle: Hello\ndate: 2025-01-01\n---\n\nFinal version.\n")})
+	if err := blog.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	for path, since := range lastModified {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("If-Modified-Since", since)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Final version.") {
+			t.Errorf("%s: expected the edited post after reload, got %d", path, w.Code)
+		}
+	}
+}
+
+// compression
+
+func TestHandler_Compression(t *testing.T) {
+	blog := blogWithPosts(t, "---\ntitle: Hello\ndate: 2025-01-01\n---\n\nContent.\n")
+	handler := blog.Handler()
+
+	get := func(path, acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/hello", "/", "/feed.xml", "/_themes/default.css"} {
+		plain := get(path, "", "")
+		w := get(path, "br;q=1, gzip;q=0.8", "")
+		if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("%s: Content-Encoding: got %q, want gzip", path, got)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%s: Vary: got %q, want Accept-Encoding", path, got)
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if string(body) != plain.Body.String() {
+			t.Errorf("%s: expected decompressed body to match the uncompressed one", path)
+		}
+
+		etag := w.Header().Get("ETag")
+		if etag == plain.Header().Get("ETag") {
+			t.Errorf("%s: expected compressed response to have its own ETag", path)
+		}
+		if w := get(path, "gzip", etag); w.Code != http.StatusNotModified {
+			t.Errorf("%s: expected 304 for matching compressed ETag, got %d", path, w.Code)
+		}
+		if w := get(path, "gzip;q=0", ""); w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected no compression when gzip is refused", path)
+		}
+	}
+}
+
+type trimEncoder struct{}
+
+func (trimEncoder) Encoding() string { return "x-test" }
+
+func (trimEncoder) Encode(data []byte) ([]byte, error) {
+	return bytes.TrimSpace(data), nil
+}
+
+func TestHandler_CustomEncoders(t *testing.T) {
+	dir := t.TempDir()
+	writePost(t, dir, "hello.md", "---\ntitle: Hello\n---\n\nContent.\n")
+
+	blog, err := New(Config{ContentDir: dir, Encoders: []Encoder{trimEncoder{}, GzipEncoder(gzip.BestSpeed)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/hello", nil)
+	req.Header.Set("Accept-Encoding", "gzip, x-test")
+	w := httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+	if got := w.Header().Get("Content-Encoding"); got != "x-test" {
+		t.Errorf("Content-Encoding: got %q, want the first configured encoder", got)
+	}
+
+	blog, err = New(Config{ContentDir: dir, Encoders: []Encoder{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	blog.Handler().ServeHTTP(w, req)
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("expected compression to be disabled, got Content-Encoding %q", got)
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	gz := GzipEncoder(gzip.DefaultCompression)
+	encoders := []Encoder{trimEncoder{}, gz}
+	cases := []struct {
+		header string
+		want   Encoder
+	}{
+		{"", nil},
+		{"gzip", gz},
+		{"GZIP, deflate", gz},
+		{"x-test;q=0, gzip", gz},
+		{"gzip;q=0.5, x-test;q=0.1", trimEncoder{}},
+		{"*", trimEncoder{}},
+		{"*;q=0", nil},
+		{"identity", nil},
+	}
+	for _, c := range cases {
+		if got := negotiateEncoding(c.header, encoders); got != c.want {
+			t.Errorf("negotiateEncoding(%q): got %v, want %v", c.header, got, c.want)
+		}
+	}
+}
+
+// benchmarks
+
+var (
+	benchBlogOnce sync.Once
+	benchBlog     *Blog
+)
+
+// largeBlog returns a blog of 10,000 posts spread across 50 tags, built once
+// and shared by the benchmarks.
+func largeBlog(b *testing.B) *Blog {
+	b.Helper()
+	benchBlogOnce.Do(func() {
+		fsys := fstest.MapFS{}
+		start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := range 10000 {
+			fsys[fmt.Sprintf("post-%d.md", i)] = &fstest.MapFile{Data: []byte(fmt.Sprintf(
+				"---\ntitle: Post %d\ndate: %s\ntags: [tag-%d]\n---\n\nContent of post %d.\n",
+				i, start.AddDate(0, 0, i).Format("2006-01-02"), i%50, i))}
+		}
+		blog, err := New(Config{ContentFS: fsys})
+		if err != nil {
+			panic(err)
+		}
+		benchBlog = blog
+	})
+	return benchBlog
+}
+
+func BenchmarkLookupPost(b *testing.B) {
+	snap := largeBlog(b).snapshot()
+	slug := "post-0" // the oldest post, last in the list
+
+	b.Run("linear", func(b *testing.B) {
+		for b.Loop() {
+			for _, post := range snap.posts {
+				if post.Slug == slug {
+					break
+				}
+			}
+		}
+	})
+	b.Run("indexed", func(b *testing.B) {
+		for b.Loop() {
+			snap.post(slug)
+		}
+	})
+}
+
+func BenchmarkLookupTag(b *testing.B) {
+	snap := largeBlog(b).snapshot()
+
+	b.Run("linear", func(b *testing.B) {
+		for b.Loop() {
+			var posts []Post
+			for _, post := range snap.posts {
+				if slices.ContainsFunc(post.Tags, func(t string) bool { return tagSlug(t) == "tag-7" }) {
+					posts = append(posts, post)
+				}
+			}
+		}
+	})
+	b.Run("indexed", func(b *testing.B) {
+		for b.Loop() {
+			snap.postsWithTag("tag-7")
+		}
+	})
+}
+
+func BenchmarkHandler_SinglePost(b *testing.B) {
+	handler := largeBlog(b).Handler()
+	req := httptest.NewRequest("GET", "/post-0", nil)
+	for b.Loop() {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkHandler_TaggedPosts(b *testing.B) {
+	handler := largeBlog(b).Handler()
+	req := httptest.NewRequest("GET", "/_tags/tag-7", nil)
+	for b.Loop() {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+// helpers
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use, for capturing
+// log output written from background goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+// memSource is an in-memory ContentSource.
+type memSource struct {
+	mu   sync.Mutex
+	docs []Document
+}
+
+func (s *memSource) Documents() ([]Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.docs), nil
+}
+
+func (s *memSource) set(docs ...Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs = docs
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func memPost(content string) (fs.FS, string) {
+	return fstest.MapFS{"post.md": {Data: []byte(content)}}, "post.md"
 }
 
 func writePost(t *testing.T, dir, name, content string) {
@@ -3,6 +3,10 @@ module github.com/josephburgess/glogger
 go 1.24.1
 
 require (
+	github.com/alecthomas/chroma/v2 v2.20.0
 	github.com/yuin/goldmark v1.7.8
+	github.com/yuin/goldmark-highlighting/v2 v2.0.0-20230729083705-37449abec8cc
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/dlclark/regexp2 v1.11.5 // indirect
@@ -1,10 +1,11 @@
 package glogger
 
 import (
-	"encoding/xml"
+	"io/fs"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
-	"time"
 )
 
 // Handler returns an http.Handler that serves the blog.
@@ -14,62 +15,105 @@ import (
 func (b *Blog) Handler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /{$}", b.handleListPosts)
+	mux.HandleFunc("GET /page/{n}", b.handleListPosts)
 	mux.HandleFunc("GET /feed.xml", b.handleFeed)
+	mux.HandleFunc("GET /atom.xml", b.handleAtomFeed)
+	mux.HandleFunc("GET /feed.json", b.handleJSONFeed)
+	mux.HandleFunc("GET /sitemap.xml", b.handleSitemap)
+	mux.HandleFunc("GET /_tags/{$}", b.handleTagIndex)
 	mux.HandleFunc("GET /_tags/{tag}", b.handleTaggedPosts)
+	mux.HandleFunc("GET /_tags/{tag}/page/{n}", b.handleTaggedPosts)
+	mux.HandleFunc("GET /_tags/{tag}/feed.xml", b.handleTagFeed)
+	mux.HandleFunc("GET /_archive/{$}", b.handleArchiveIndex)
+	mux.HandleFunc("GET /_archive/{year}", b.handleArchivePeriod)
+	mux.HandleFunc("GET /_archive/{year}/{month}", b.handleArchivePeriod)
+	mux.HandleFunc("GET /_search", b.handleSearch)
+	mux.HandleFunc("GET /_search/index.json", b.handleSearchIndex)
+	mux.HandleFunc("GET /_search/search.js", b.handleSearchScript)
+	mux.HandleFunc("GET /_api/posts", b.handleAPIPosts)
+	mux.HandleFunc("GET /_api/posts/{slug...}", b.handleAPIPost)
+	mux.HandleFunc("GET /_api/tags", b.handleAPITags)
+	mux.HandleFunc("GET /_api/", b.handleAPINotFound)
 	mux.HandleFunc("GET /_themes/{theme}", b.handleThemeCSS)
-	mux.HandleFunc("GET /{slug}", b.handleSinglePost)
+	mux.HandleFunc("GET /_themes/syntax/{style}", b.handleSyntaxCSS)
+	mux.HandleFunc("GET /_assets/{path...}", b.handleAsset)
+	mux.HandleFunc("GET /{slug...}", b.handleSinglePost)
 	return mux
 }
 
 func (b *Blog) handleSinglePost(w http.ResponseWriter, r *http.Request) {
-	slug := r.PathValue("slug")
-
-	for _, post := range b.posts {
-		if post.Slug == slug {
-			html, err := b.renderer.renderPost(post)
-			if err != nil {
-				http.Error(w, "Error rendering post: "+err.Error(), http.StatusInternalServerError)
-				return
-			}
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.Write([]byte(html))
-			return
-		}
+	snap := b.snapshot()
+	post, ok := snap.post(r.PathValue("slug"))
+	if !ok {
+		http.NotFound(w, r)
+		return
 	}
 
-	http.NotFound(w, r)
+	p, err := snap.pages.render("/"+post.Slug, htmlContentType, snap.postModTime(post), func() (string, error) {
+		return b.renderer.renderPost(post)
+	})
+	if err != nil {
+		http.Error(w, "Error rendering post: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Pages)
 }
 
 func (b *Blog) handleListPosts(w http.ResponseWriter, r *http.Request) {
-	html, err := b.renderer.renderPostList(b.posts, "")
+	page, ok := pageNumber(r.PathValue("n"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	snap := b.snapshot()
+	posts, pagination, ok := paginate(snap.posts, page, b.config.PostsPerPage, b.config.URLPrefix+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	p, err := snap.pages.render(pageURL("/", page), htmlContentType, snap.modTime, func() (string, error) {
+		return b.renderer.renderPostList(ListTemplateData{Posts: posts, Pagination: pagination})
+	})
 	if err != nil {
 		http.Error(w, "Error rendering post list: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(html))
+	b.writePage(w, r, p, b.config.CacheControl.Pages)
 }
 
 func (b *Blog) handleTaggedPosts(w http.ResponseWriter, r *http.Request) {
-	tag := r.PathValue("tag")
-
-	var filtered []Post
-	for _, post := range b.posts {
-		for _, t := range post.Tags {
-			if t == tag {
-				filtered = append(filtered, post)
-				break
-			}
-		}
+	snap := b.snapshot()
+	slug := tagSlug(r.PathValue("tag"))
+
+	filtered := snap.postsWithTag(slug)
+
+	page, ok := pageNumber(r.PathValue("n"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	posts, pagination, ok := paginate(filtered, page, b.config.PostsPerPage, b.config.URLPrefix+"/_tags/"+slug)
+	if !ok {
+		http.NotFound(w, r)
+		return
 	}
 
-	html, err := b.renderer.renderPostList(filtered, tag)
+	// Any tag name can be requested, so only cache the pages of tags in use.
+	key := ""
+	if len(filtered) > 0 {
+		key = pageURL("/_tags/"+slug, page)
+	}
+	p, err := snap.pages.render(key, htmlContentType, snap.modTime, func() (string, error) {
+		return b.renderer.renderPostList(ListTemplateData{Posts: posts, Tag: snap.tagLabel(slug), TagSlug: slug, Pagination: pagination})
+	})
 	if err != nil {
 		http.Error(w, "Error rendering tag page: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Write([]byte(html))
+	b.writePage(w, r, p, b.config.CacheControl.Pages)
 }
 
 func (b *Blog) handleThemeCSS(w http.ResponseWriter, r *http.Request) {
@@ -81,88 +125,23 @@ func (b *Blog) handleThemeCSS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	content, err := themeFS.ReadFile("assets/themes/" + theme + ".css")
+	p, err := assetPage(themeFS, "assets/themes/"+theme+".css", "text/css")
 	if err != nil {
 		http.Error(w, "Theme not found: "+err.Error(), http.StatusNotFound)
 		return
 	}
-
-	w.Header().Set("Content-Type", "text/css")
-	w.Write(content)
-}
-
-type rssItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
-	GUID        string `xml:"guid"`
-}
-
-type rssChannel struct {
-	XMLName       xml.Name  `xml:"channel"`
-	Title         string    `xml:"title"`
-	Link          string    `xml:"link"`
-	Description   string    `xml:"description"`
-	LastBuildDate string    `xml:"lastBuildDate"`
-	Items         []rssItem `xml:"item"`
-}
-
-type rssFeed struct {
-	XMLName xml.Name   `xml:"rss"`
-	Version string     `xml:"version,attr"`
-	Channel rssChannel
-}
-
-func (b *Blog) handleFeed(w http.ResponseWriter, r *http.Request) {
-	baseURL := strings.TrimRight(b.config.BaseURL, "/")
-
-	items := make([]rssItem, 0, len(b.posts))
-	for _, post := range b.posts {
-		link := baseURL + b.config.URLPrefix + "/" + post.Slug
-		pubDate := ""
-		if !post.PublishDate.IsZero() {
-			pubDate = post.PublishDate.UTC().Format(time.RFC1123Z)
-		}
-		items = append(items, rssItem{
-			Title:       post.Title,
-			Link:        link,
-			Description: post.Description,
-			PubDate:     pubDate,
-			GUID:        link,
-		})
-	}
-
-	lastBuild := ""
-	if len(b.posts) > 0 && !b.posts[0].PublishDate.IsZero() {
-		lastBuild = b.posts[0].PublishDate.UTC().Format(time.RFC1123Z)
-	}
-
-	feed := rssFeed{
-		Version: "2.0",
-		Channel: rssChannel{
-			Title:         b.config.Title,
-			Link:          baseURL + b.config.URLPrefix,
-			Description:   b.config.Description,
-			LastBuildDate: lastBuild,
-			Items:         items,
-		},
-	}
-
-	out, err := xml.MarshalIndent(feed, "", "  ")
-	if err != nil {
-		http.Error(w, "Error generating feed: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
-	w.Write([]byte(xml.Header))
-	w.Write(out)
+	b.writePage(w, r, p, b.config.CacheControl.Assets)
 }
 
 // PostHandler returns a standalone handler for rendering a single markdown file.
 // Useful for serving a specific post outside the blog structure.
 func PostHandler(postPath string, theme string) http.HandlerFunc {
+	return PostHandlerFS(os.DirFS(filepath.Dir(postPath)), filepath.Base(postPath), theme)
+}
+
+// PostHandlerFS is like PostHandler but reads the markdown file from fsys,
+// e.g. a file embedded in the binary with //go:embed.
+func PostHandlerFS(fsys fs.FS, name string, theme string) http.HandlerFunc {
 	cfg := Config{Theme: theme}
 	cfg.setDefaults()
 
@@ -173,7 +152,7 @@ func PostHandler(postPath string, theme string) http.HandlerFunc {
 		}
 	}
 
-	post, err := parsePost(postPath, newMarkdown())
+	post, err := parsePost(fsys, name, newMarkdown(cfg))
 	if err != nil {
 		return func(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Error parsing post: "+err.Error(), http.StatusInternalServerError)
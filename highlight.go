@@ -0,0 +1,71 @@
+package glogger
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// chromaStyleAliases maps highlight.js theme names to the closest chroma
+// style, for the common themes whose names differ.
+var chromaStyleAliases = map[string]string{
+	"atom-one-dark":     "onedark",
+	"monokai-sublime":   "monokai",
+	"tokyo-night-dark":  "tokyonight-night",
+	"tokyo-night-light": "tokyonight-day",
+}
+
+// chromaStyle returns the chroma style used for server-side highlighting: the
+// one named by SyntaxTheme, or the default for Theme if there's no such style.
+func chromaStyle(config Config) string {
+	if name, ok := lookupChromaStyle(config.SyntaxTheme); ok {
+		return name
+	}
+	return defaultSyntaxTheme(config.Theme)
+}
+
+// lookupChromaStyle returns the chroma style matching the syntax theme name,
+// reporting whether there is one.
+func lookupChromaStyle(name string) (string, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := chromaStyleAliases[name]; ok {
+		name = alias
+	}
+	_, ok := styles.Registry[name]
+	return name, ok
+}
+
+// syntaxCSS generates the stylesheet for code highlighted server-side with
+// the chroma style name.
+func syntaxCSS(name string) ([]byte, error) {
+	style, ok := styles.Registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown syntax style %q", name)
+	}
+	var buf bytes.Buffer
+	if err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, style); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *Blog) handleSyntaxCSS(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSuffix(r.PathValue("style"), ".css")
+	if _, ok := styles.Registry[name]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	p, err := staticPage("syntax/"+name, "text/css; charset=utf-8", func() ([]byte, error) {
+		return syntaxCSS(name)
+	})
+	if err != nil {
+		http.Error(w, "Error generating syntax CSS: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Assets)
+}
@@ -1,7 +1,11 @@
 package glogger
 
 import (
+	"compress/gzip"
 	"html/template"
+	"io/fs"
+	"log"
+	"os"
 	"time"
 )
 
@@ -9,47 +13,149 @@ type Post struct {
 	Title       string
 	Content     template.HTML
 	PublishDate time.Time
+	Updated     time.Time // from the "updated" frontmatter field; zero if the post hasn't been revised
+	Author      string
 	Slug        string
 	Description string
 	Tags        []string
 	Draft       bool
+
+	markdown string    // source, without frontmatter
+	modTime  time.Time // modification time of the source document; zero if unknown
 }
 
 type Config struct {
-	ContentDir  string // directory containing markdown files
-	URLPrefix   string // URL prefix for the blog (e.g. "/blog")
-	Theme       string // theme name: "default", "dark", "light", "rosepine"
-	SyntaxTheme string // highlight.js theme name (e.g. "rose-pine", "github-dark"); defaults to best match for Theme
-	Title       string // blog title used in RSS feed channel (default: "Blog")
-	Description string // blog description used in RSS feed channel (optional)
-	BaseURL     string // base URL of the site (e.g. "https://example.com") — used to build absolute links in RSS feed
+	ContentDir      string        // directory containing markdown files
+	ContentFS       fs.FS         // filesystem containing markdown files (e.g. an embed.FS); overrides ContentDir when set
+	Source          ContentSource // where posts are loaded from; overrides ContentFS and ContentDir (default: FSSource(ContentFS))
+	NestedSlugs     bool          // derive slugs from the path relative to the content root (e.g. "2024/hello") rather than the filename
+	URLPrefix       string        // URL prefix for the blog (e.g. "/blog")
+	Theme           string        // theme name: "default", "dark", "light", "rosepine"
+	SyntaxTheme     string        // highlight.js theme name (e.g. "rose-pine", "github-dark"), or chroma style with ServerHighlight; defaults to best match for Theme
+	ServerHighlight bool          // highlight code blocks when posts are parsed rather than in the browser with highlight.js
+	Offline         bool          // serve fonts from the blog itself and highlight server-side, so pages load nothing from third-party CDNs
+	TemplateDir     string        // directory of templates overriding the built-in ones (optional)
+	TemplatesFS     fs.FS         // filesystem of templates overriding the built-in ones; overrides TemplateDir when set
+	Title           string        // blog title used in RSS feed channel (default: "Blog")
+	Description     string        // blog description used in RSS feed channel (optional)
+	BaseURL         string        // base URL of the site (e.g. "https://example.com") — used to build absolute links in RSS feed
+	PostsPerPage    int           // number of posts per list page; 0 shows all posts on one page
+
+	Author          string // default author name for feeds (default: Title)
+	FeedFullContent bool   // include the full rendered post in RSS and Atom feeds, not just the description
+	FeedLimit       int    // maximum number of posts in feeds, newest first; 0 includes every post
+
+	EnableSearch bool // add a search box with instant results to post and list pages
+
+	TagAliases map[string]string // maps tags to the tag they should be treated as (e.g. "golang" -> "go"); keys are case-insensitive
+
+	Watch          bool          // reload posts automatically when the content changes
+	ReloadInterval time.Duration // how often to check for changes when watching (default: 2s)
+	ErrorLog       *log.Logger   // logger for reload errors (default: log.Default())
+
+	PageCacheMaxBytes int64 // upper bound on memory used by cached rendered pages; 0 means no bound, negative disables the cache
+
+	CacheControl CachePolicy // Cache-Control header for each kind of response
+	Encoders     []Encoder   // compression offered to clients, most preferred first (default: gzip); set to an empty slice to disable
+}
+
+// CachePolicy sets the Cache-Control header sent with each kind of response.
+// Every response also carries an ETag and, where known, a Last-Modified date,
+// so clients can cheaply revalidate whatever they cache.
+type CachePolicy struct {
+	Pages  string // post, list, tag, archive and search pages (default: "no-cache")
+	Feeds  string // RSS, Atom and JSON feeds, the sitemap and the search index (default: "no-cache")
+	Assets string // theme CSS and scripts (default: "public, max-age=86400")
+	API    string // JSON API responses (default: "no-cache")
+}
+
+// lastUpdated returns when the post was last changed: its updated date if set,
+// otherwise its publish date.
+func (p Post) lastUpdated() time.Time {
+	if p.Updated.After(p.PublishDate) {
+		return p.Updated
+	}
+	return p.PublishDate
+}
+
+// lastModified returns when the post's rendered page last changed: the later
+// of its updated date and its source document's modification time.
+func (p Post) lastModified() time.Time {
+	if p.modTime.After(p.lastUpdated()) {
+		return p.modTime
+	}
+	return p.lastUpdated()
 }
 
 type PostTemplateData struct {
 	Post
+	BlogTitle    string
 	BlogPrefix   string
 	ThemeCSS     string
 	HighlightCSS string
+	HighlightJS  string
+	FontCSS      string
+	Offline      bool
+	EnableSearch bool
 }
 
 type ListTemplateData struct {
-	Posts      []Post
+	Posts        []Post
+	BlogPrefix   string
+	ThemeCSS     string
+	BlogTitle    string // from config.Title
+	FontCSS      string
+	Offline      bool
+	EnableSearch bool
+	Tag          string                   // display label of the tag, non-empty when filtering by tag
+	TagSlug      string                   // URL-safe form of Tag
+	Archive      string                   // period being listed (e.g. "March 2025"), non-empty on archive pages
+	Search       bool                     // true on the search page
+	Query        string                   // search query
+	Snippets     map[string]template.HTML // search result snippets by post slug
+	Pagination
+}
+
+type TagIndexTemplateData struct {
+	Tags       []TagCount
+	Sort       string // TagSortName or TagSortCount
+	BlogPrefix string
+	ThemeCSS   string
+	BlogTitle  string
+	FontCSS    string
+	Offline    bool
+}
+
+type ArchiveTemplateData struct {
+	Years      []ArchiveYear
 	BlogPrefix string
 	ThemeCSS   string
-	BlogTitle  string // from config.Title
-	Tag        string // non-empty when filtering by tag
+	BlogTitle  string
+	FontCSS    string
+	Offline    bool
 }
 
 type templateRenderer struct {
-	postTemplate *template.Template
-	listTemplate *template.Template
-	config       Config
+	postTemplate    *template.Template
+	listTemplate    *template.Template
+	tagsTemplate    *template.Template
+	archiveTemplate *template.Template
+	config          Config
 }
 
 func (c *Config) setDefaults() {
 	if c.ContentDir == "" {
 		c.ContentDir = "content/posts"
 	}
+	if c.ContentFS == nil {
+		c.ContentFS = os.DirFS(c.ContentDir)
+	}
+	if c.Source == nil {
+		c.Source = FSSource(c.ContentFS)
+	}
+	if c.TemplatesFS == nil && c.TemplateDir != "" {
+		c.TemplatesFS = os.DirFS(c.TemplateDir)
+	}
 	if c.URLPrefix == "" {
 		c.URLPrefix = "/blog"
 	}
@@ -59,7 +165,20 @@ func (c *Config) setDefaults() {
 	if c.SyntaxTheme == "" {
 		c.SyntaxTheme = defaultSyntaxTheme(c.Theme)
 	}
+	if c.Offline {
+		c.ServerHighlight = true
+	}
 	if c.Title == "" {
 		c.Title = "Blog"
 	}
+	if c.ReloadInterval <= 0 {
+		c.ReloadInterval = 2 * time.Second
+	}
+	if c.ErrorLog == nil {
+		c.ErrorLog = log.Default()
+	}
+	c.CacheControl.setDefaults()
+	if c.Encoders == nil {
+		c.Encoders = []Encoder{GzipEncoder(gzip.DefaultCompression)}
+	}
 }
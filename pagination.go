@@ -0,0 +1,64 @@
+package glogger
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Pagination describes the current page of a post list.
+type Pagination struct {
+	Page       int    // current page, starting at 1
+	TotalPages int    // always at least 1
+	PrevURL    string // newer posts; empty on the first page
+	NextURL    string // older posts; empty on the last page
+}
+
+// paginate returns the posts on the given page (1-based) along with links to the
+// neighbouring pages. baseURL is the URL of the first page; later pages live
+// at baseURL + "/page/{n}". ok is false if the page doesn't exist.
+func paginate(posts []Post, page, perPage int, baseURL string) (paged []Post, p Pagination, ok bool) {
+	if perPage <= 0 {
+		perPage = len(posts)
+	}
+
+	total := 1
+	if perPage > 0 {
+		total = (len(posts) + perPage - 1) / perPage
+	}
+	total = max(total, 1)
+	if page < 1 || page > total {
+		return nil, Pagination{}, false
+	}
+
+	start := min((page-1)*perPage, len(posts))
+	end := min(start+perPage, len(posts))
+
+	p = Pagination{Page: page, TotalPages: total}
+	if page > 1 {
+		p.PrevURL = pageURL(baseURL, page-1)
+	}
+	if page < total {
+		p.NextURL = pageURL(baseURL, page+1)
+	}
+	return posts[start:end], p, true
+}
+
+func pageURL(baseURL string, page int) string {
+	if page == 1 {
+		return baseURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/page/" + strconv.Itoa(page)
+}
+
+// pageNumber parses the {n} path value of a paginated route. Routes without
+// one are the first page.
+func pageNumber(s string) (int, bool) {
+	if s == "" {
+		return 1, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
-	"os"
+	"io/fs"
 	"strings"
 	"time"
 
@@ -14,19 +14,26 @@ import (
 
 type frontmatter struct {
 	Title       string   `yaml:"title"`
+	Slug        string   `yaml:"slug"`
 	Date        string   `yaml:"date"`
+	Updated     string   `yaml:"updated"`
+	Author      string   `yaml:"author"`
 	Description string   `yaml:"description"`
 	Tags        []string `yaml:"tags"`
 	Draft       bool     `yaml:"draft"`
 }
 
-func parsePost(filename string, md goldmark.Markdown) (Post, error) {
-	content, err := os.ReadFile(filename)
+func parsePost(fsys fs.FS, filename string, md goldmark.Markdown) (Post, error) {
+	content, err := fs.ReadFile(fsys, filename)
 	if err != nil {
 		return Post{}, err
 	}
+	return parseDocument(Document{Path: filename, Content: content}, md)
+}
 
-	raw := string(content)
+func parseDocument(doc Document, md goldmark.Markdown) (Post, error) {
+	filename := doc.Path
+	raw := string(doc.Content)
 
 	if !strings.HasPrefix(raw, "---\n") {
 		return Post{}, fmt.Errorf("missing frontmatter in %s", filename)
@@ -47,24 +54,39 @@ func parsePost(filename string, md goldmark.Markdown) (Post, error) {
 		title = "Untitled Post"
 	}
 
-	var publishDate time.Time
-	if fm.Date != "" {
-		if d, err := time.Parse("2006-01-02", fm.Date); err == nil {
-			publishDate = d
-		}
-	}
+	publishDate := parseDate(fm.Date)
+
+	body := strings.TrimSpace(parts[2])
 
 	var buf bytes.Buffer
-	if err := md.Convert([]byte(strings.TrimSpace(parts[2])), &buf); err != nil {
+	if err := md.Convert([]byte(body), &buf); err != nil {
 		return Post{}, err
 	}
 
 	return Post{
 		Title:       title,
+		Slug:        strings.Trim(fm.Slug, "/"),
 		Content:     template.HTML(buf.String()),
 		PublishDate: publishDate,
+		Updated:     parseDate(fm.Updated),
+		Author:      fm.Author,
 		Description: fm.Description,
 		Tags:        fm.Tags,
 		Draft:       fm.Draft,
+		markdown:    body,
+		modTime:     doc.ModTime,
 	}, nil
 }
+
+// parseDate parses a YYYY-MM-DD frontmatter date. Missing or invalid dates are
+// ignored and return the zero time.
+func parseDate(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}
+	}
+	return d
+}
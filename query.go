@@ -0,0 +1,132 @@
+package glogger
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortOrder is the order of posts returned by Blog.Query.
+type SortOrder int
+
+const (
+	SortNewest SortOrder = iota // newest first (default)
+	SortOldest                  // oldest first
+	SortTitle                   // alphabetical by title, ignoring case
+)
+
+// QueryOptions selects posts for Blog.Query. The zero value matches every post.
+type QueryOptions struct {
+	Tag    string    // only posts with this tag; "Go" and "go" are the same tag
+	From   time.Time // only posts published at or after From (optional)
+	To     time.Time // only posts published before To (optional)
+	Sort   SortOrder
+	Offset int // number of matching posts to skip
+	Limit  int // maximum number of posts to return; 0 means no limit
+}
+
+// GetPost returns the published post with the given slug.
+func (b *Blog) GetPost(slug string) (Post, bool) {
+	post, ok := b.snapshot().post(slug)
+	if !ok {
+		return Post{}, false
+	}
+	return copyPost(post), true
+}
+
+// PostsByTag returns the published posts with the given tag, newest first.
+func (b *Blog) PostsByTag(tag string) []Post {
+	return copyPosts(b.snapshot().postsWithTag(tagSlug(tag)))
+}
+
+// PostsBetween returns the posts published at or after from and before to,
+// newest first. A zero from or to leaves that end of the range open.
+func (b *Blog) PostsBetween(from, to time.Time) []Post {
+	return copyPosts(postsBetween(b.snapshot().posts, from, to))
+}
+
+// Query returns the posts matching opts.
+func (b *Blog) Query(opts QueryOptions) []Post {
+	return copyPosts(b.snapshot().query(opts))
+}
+
+// post returns the post with the given slug.
+func (s *snapshot) post(slug string) (Post, bool) {
+	i, ok := s.bySlug[slug]
+	if !ok {
+		return Post{}, false
+	}
+	return s.posts[i], true
+}
+
+// postsWithTag returns the posts with the tag identified by slug, newest first.
+func (s *snapshot) postsWithTag(slug string) []Post {
+	return s.byTag[slug]
+}
+
+// query returns the posts matching opts. The result may share memory with the
+// snapshot and must not be modified.
+func (s *snapshot) query(opts QueryOptions) []Post {
+	posts := s.posts
+	if opts.Tag != "" {
+		posts = s.postsWithTag(tagSlug(opts.Tag))
+	}
+	posts = postsBetween(posts, opts.From, opts.To)
+
+	switch opts.Sort {
+	case SortOldest:
+		posts = slices.Clone(posts)
+		slices.Reverse(posts)
+	case SortTitle:
+		posts = slices.Clone(posts)
+		sort.SliceStable(posts, func(i, j int) bool {
+			return strings.ToLower(posts[i].Title) < strings.ToLower(posts[j].Title)
+		})
+	}
+
+	start := min(max(opts.Offset, 0), len(posts))
+	posts = posts[start:]
+	if opts.Limit > 0 && len(posts) > opts.Limit {
+		posts = posts[:opts.Limit]
+	}
+	return posts
+}
+
+// postsBetween returns the sub-slice of posts (sorted newest first) published
+// in [from, to). Zero bounds are open, but undated posts, which sort last,
+// are left out whenever either bound is set.
+func postsBetween(posts []Post, from, to time.Time) []Post {
+	if from.IsZero() && to.IsZero() {
+		return posts
+	}
+	end := sort.Search(len(posts), func(i int) bool {
+		return posts[i].PublishDate.IsZero()
+	})
+	if !from.IsZero() {
+		end = sort.Search(end, func(i int) bool {
+			return posts[i].PublishDate.Before(from)
+		})
+	}
+	start := 0
+	if !to.IsZero() {
+		start = sort.Search(end, func(i int) bool {
+			return posts[i].PublishDate.Before(to)
+		})
+	}
+	return posts[start:end]
+}
+
+// copyPost returns a copy of post that doesn't share memory with the snapshot.
+func copyPost(post Post) Post {
+	post.Tags = slices.Clone(post.Tags)
+	return post
+}
+
+func copyPosts(posts []Post) []Post {
+	result := make([]Post, len(posts))
+	for i, post := range posts {
+		result[i] = copyPost(post)
+	}
+	return result
+}
@@ -0,0 +1,290 @@
+package glogger
+
+import (
+	"html"
+	"html/template"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SearchResult is a post matching a search query.
+type SearchResult struct {
+	Post    Post
+	Score   float64       // relevance; higher is better
+	Snippet template.HTML // excerpt of the post with matching words wrapped in <mark>
+}
+
+// field weights: a match in the title counts for more than one in the body
+const (
+	searchWeightTitle       = 5
+	searchWeightTags        = 3
+	searchWeightDescription = 2
+	searchWeightBody        = 1
+)
+
+// searchIndex is an inverted index over the posts of a snapshot, built once per
+// load. Document ids are indexes into snapshot.posts.
+type searchIndex struct {
+	postings map[string][]searchPosting // stemmed term -> documents containing it
+	text     []string                   // plain text of each post, for snippets
+	terms    [][]string                 // sorted unique terms of each post, for the client-side index
+}
+
+type searchPosting struct {
+	doc    int
+	weight float64 // field-weighted term frequency
+}
+
+var (
+	wordPattern  = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+func buildSearchIndex(posts []Post) *searchIndex {
+	idx := &searchIndex{
+		postings: map[string][]searchPosting{},
+		text:     make([]string, len(posts)),
+		terms:    make([][]string, len(posts)),
+	}
+
+	for doc, post := range posts {
+		idx.text[doc] = plainText(post.Content)
+
+		counts := map[string]float64{}
+		add := func(text string, weight float64) {
+			for _, term := range tokenize(text) {
+				counts[term] += weight
+			}
+		}
+		add(post.Title, searchWeightTitle)
+		add(strings.Join(post.Tags, " "), searchWeightTags)
+		add(post.Description, searchWeightDescription)
+		add(idx.text[doc], searchWeightBody)
+
+		terms := make([]string, 0, len(counts))
+		for term, weight := range counts {
+			idx.postings[term] = append(idx.postings[term], searchPosting{doc: doc, weight: weight})
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+		idx.terms[doc] = terms
+	}
+	return idx
+}
+
+// search returns the posts containing every term of query, best match first.
+// Scores are TF-IDF: terms that appear in fewer posts count for more, and
+// repeated occurrences have diminishing returns.
+func (idx *searchIndex) search(posts []Post, query string) []SearchResult {
+	terms := uniqueTerms(tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := map[int]float64{}
+	for i, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			return nil
+		}
+		idf := math.Log(1 + float64(len(posts))/float64(len(postings)))
+
+		matched := make(map[int]float64, len(postings))
+		for _, p := range postings {
+			if _, ok := scores[p.doc]; i == 0 || ok {
+				matched[p.doc] = scores[p.doc] + (1+math.Log(p.weight))*idf
+			}
+		}
+		scores = matched
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for doc, score := range scores {
+		text := idx.text[doc]
+		if text == "" {
+			text = posts[doc].Description
+		}
+		results = append(results, SearchResult{
+			Post:    posts[doc],
+			Score:   score,
+			Snippet: snippet(text, terms),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Post.PublishDate.After(results[j].Post.PublishDate)
+	})
+	return results
+}
+
+// Search returns the published posts matching query, best match first. A post
+// matches if its title, tags, description or content contain every word of
+// the query (ignoring case, common words and simple word endings, so "running"
+// matches "run").
+func (b *Blog) Search(query string) []SearchResult {
+	snap := b.snapshot()
+	return snap.search.search(snap.posts, query)
+}
+
+func (b *Blog) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	results := b.Search(query)
+	data := ListTemplateData{
+		Posts:    make([]Post, 0, len(results)),
+		Search:   true,
+		Query:    query,
+		Snippets: make(map[string]template.HTML, len(results)),
+	}
+	for _, result := range results {
+		data.Posts = append(data.Posts, result.Post)
+		data.Snippets[result.Post.Slug] = result.Snippet
+	}
+
+	html, err := b.renderer.renderPostList(data)
+	if err != nil {
+		http.Error(w, "Error rendering search results: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Queries are arbitrary, so results aren't cached server-side.
+	b.writePage(w, r, newPage([]byte(html), htmlContentType, b.snapshot().modTime), b.config.CacheControl.Pages)
+}
+
+// tokenize splits text into lower-cased, stemmed search terms, dropping
+// single characters and common English words.
+func tokenize(text string) []string {
+	words := wordPattern.FindAllString(strings.ToLower(text), -1)
+	terms := words[:0]
+	for _, word := range words {
+		if len(word) < 2 || stopWords[word] {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := map[string]bool{}
+	result := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// stem strips common English suffixes so that different forms of a word are
+// indexed together ("posts", "posted", "posting" -> "post"). It is deliberately
+// simple, and always leaves at least three characters. assets/js/search.js has
+// a copy for client-side search; keep them in sync.
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ing") && len(word) > 5:
+		return undouble(word[:len(word)-3])
+	case strings.HasSuffix(word, "ed") && len(word) > 4:
+		return undouble(word[:len(word)-2])
+	case strings.HasSuffix(word, "ly") && len(word) > 4:
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "es") && len(word) > 4 && strings.ContainsAny(word[len(word)-3:len(word)-2], "sxz"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && len(word) > 3:
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// undouble removes a doubled final consonant left by stripping a suffix
+// ("runn" -> "run"), except for l, s and z ("fall", "pass", "buzz").
+func undouble(word string) string {
+	n := len(word)
+	if n > 3 && word[n-1] == word[n-2] && !strings.ContainsRune("aeiouylsz", rune(word[n-1])) {
+		return word[:n-1]
+	}
+	return word
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"so": true, "that": true, "the": true, "their": true, "then": true,
+	"there": true, "these": true, "this": true, "to": true, "was": true,
+	"were": true, "will": true, "with": true,
+}
+
+// plainText strips the markup from rendered post HTML.
+func plainText(content template.HTML) string {
+	text := tagPattern.ReplaceAllString(string(content), " ")
+	text = html.UnescapeString(text)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(text, " "))
+}
+
+// snippetLength is the approximate length, in bytes, of a search snippet.
+const snippetLength = 200
+
+// snippet returns an excerpt of text around the first word matching one of the
+// search terms, HTML-escaped, with every matching word wrapped in <mark>.
+func snippet(text string, terms []string) template.HTML {
+	want := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		want[term] = true
+	}
+	matches := func(word string) bool {
+		return want[stem(strings.ToLower(word))]
+	}
+
+	words := wordPattern.FindAllStringIndex(text, -1)
+
+	start := 0
+	for _, w := range words {
+		if matches(text[w[0]:w[1]]) {
+			// start a little before the first match, on a word boundary
+			start = max(w[0]-snippetLength/4, 0)
+			for _, v := range words {
+				if v[0] >= start {
+					start = v[0]
+					break
+				}
+			}
+			break
+		}
+	}
+	end := min(start+snippetLength, len(text))
+	for _, w := range words {
+		if w[0] < end && w[1] > end {
+			end = w[1]
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("… ")
+	}
+	last := start
+	for _, w := range words {
+		if w[0] < start || w[1] > end || !matches(text[w[0]:w[1]]) {
+			continue
+		}
+		sb.WriteString(html.EscapeString(text[last:w[0]]))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(text[w[0]:w[1]]))
+		sb.WriteString("</mark>")
+		last = w[1]
+	}
+	sb.WriteString(html.EscapeString(text[last:end]))
+	if end < len(text) {
+		sb.WriteString(" …")
+	}
+	return template.HTML(sb.String())
+}
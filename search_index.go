@@ -0,0 +1,63 @@
+package glogger
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+)
+
+//go:embed assets/js/*.js
+var scriptFS embed.FS
+
+// clientSearchDoc is a post in the client-side search index. Terms are the
+// same stemmed terms the server-side index uses, so a client only has to
+// tokenize the query the same way (see assets/js/search.js).
+type clientSearchDoc struct {
+	URL         string   `json:"url"`
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Terms       []string `json:"terms"`
+}
+
+func (b *Blog) handleSearchIndex(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	p, err := snap.pages.render("/_search/index.json", "application/json; charset=utf-8", snap.modTime, func() (string, error) {
+		return b.renderSearchIndex(snap)
+	})
+	if err != nil {
+		http.Error(w, "Error generating search index: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Feeds)
+}
+
+func (b *Blog) renderSearchIndex(snap *snapshot) (string, error) {
+	docs := make([]clientSearchDoc, 0, len(snap.posts))
+	for i, post := range snap.posts {
+		docs = append(docs, clientSearchDoc{
+			URL:         b.config.URLPrefix + "/" + post.Slug,
+			Slug:        post.Slug,
+			Title:       post.Title,
+			Description: post.Description,
+			Tags:        post.Tags,
+			Terms:       snap.search.terms[i],
+		})
+	}
+
+	out, err := json.Marshal(docs)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func (b *Blog) handleSearchScript(w http.ResponseWriter, r *http.Request) {
+	p, err := assetPage(scriptFS, "assets/js/search.js", "text/javascript; charset=utf-8")
+	if err != nil {
+		http.Error(w, "Script not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Assets)
+}
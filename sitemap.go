@@ -0,0 +1,120 @@
+package glogger
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SitemapEntry is a page of the blog to list in a sitemap.
+type SitemapEntry struct {
+	Loc     string    // URL of the page, absolute when BaseURL is set
+	LastMod time.Time // when the page's content last changed; zero if unknown
+}
+
+// SitemapEntries returns the blog index, every published post and every tag
+// page, for host apps that build their own site-wide sitemap.
+func (b *Blog) SitemapEntries() []SitemapEntry {
+	return b.sitemapEntries(b.snapshot())
+}
+
+func (b *Blog) sitemapEntries(snap *snapshot) []SitemapEntry {
+	posts := snap.posts
+
+	var newest time.Time
+	tagMod := map[string]time.Time{}
+	postEntries := make([]SitemapEntry, 0, len(posts))
+	for _, post := range posts {
+		mod := post.lastUpdated()
+		if mod.After(newest) {
+			newest = mod
+		}
+		for _, tag := range post.Tags {
+			slug := tagSlug(tag)
+			if prev, ok := tagMod[slug]; !ok || mod.After(prev) {
+				tagMod[slug] = mod
+			}
+		}
+		postEntries = append(postEntries, SitemapEntry{Loc: b.absURL("/" + post.Slug), LastMod: mod})
+	}
+
+	entries := make([]SitemapEntry, 0, 1+len(postEntries)+len(snap.tags))
+	entries = append(entries, SitemapEntry{Loc: b.absURL("/"), LastMod: newest})
+	entries = append(entries, postEntries...)
+	for _, tag := range snap.tags {
+		entries = append(entries, SitemapEntry{Loc: b.absURL("/_tags/" + tag.Slug), LastMod: tagMod[tag.Slug]})
+	}
+	return entries
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+func (b *Blog) handleSitemap(w http.ResponseWriter, r *http.Request) {
+	snap := b.snapshot()
+	p, err := snap.pages.render("/sitemap.xml", "application/xml; charset=utf-8", snap.modTime, func() (string, error) {
+		return b.renderSitemap(snap)
+	})
+	if err != nil {
+		http.Error(w, "Error generating sitemap: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Feeds)
+}
+
+func (b *Blog) renderSitemap(snap *snapshot) (string, error) {
+	entries := b.sitemapEntries(snap)
+
+	set := sitemapURLSet{URLs: make([]sitemapURL, 0, len(entries))}
+	for _, entry := range entries {
+		u := sitemapURL{Loc: entry.Loc}
+		if !entry.LastMod.IsZero() {
+			u.LastMod = entry.LastMod.UTC().Format("2006-01-02")
+		}
+		set.URLs = append(set.URLs, u)
+	}
+
+	out, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out), nil
+}
+
+// RobotsHandler returns a handler serving a robots.txt that allows all
+// crawlers and points them at the blog's sitemap. Crawlers only look for
+// robots.txt at the root of a site, so register it there yourself:
+//
+//	mux.Handle("GET /robots.txt", blog.RobotsHandler())
+//
+// The Sitemap directive must be an absolute URL, so set BaseURL in
+// production. Without it the URL is built from the request's scheme and Host
+// header, which the client controls; that's only meant for local development.
+func (b *Blog) RobotsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sitemap := b.absURL("/sitemap.xml")
+		if b.config.BaseURL == "" {
+			sitemap = requestOrigin(r) + sitemap
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "User-agent: *\nAllow: /\n\nSitemap: %s\n", sitemap)
+	}
+}
+
+// requestOrigin returns the scheme and host r was made to, e.g.
+// "http://localhost:8080".
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,80 @@
+package glogger
+
+import (
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// Document is a raw markdown post as provided by a ContentSource.
+type Document struct {
+	Path    string    // slash-separated path relative to the source root (e.g. "hello.md"); the slug is derived from it
+	Content []byte    // raw markdown, including frontmatter
+	ModTime time.Time // last modification time; zero if unknown
+}
+
+// A ContentSource provides the markdown documents a Blog is built from, e.g.
+// files on disk, rows in a database or posts held in memory by a CMS.
+//
+// Documents is called on every (re)load and, when watching, on every poll, so
+// it must be safe to call concurrently.
+type ContentSource interface {
+	Documents() ([]Document, error)
+}
+
+// A ContentVersioner is a ContentSource that can report a version of its
+// content more cheaply than reading every document, e.g. from file sizes and
+// modification times. The version must change whenever a document is added,
+// changed or removed. Watch uses it in place of Documents when available.
+type ContentVersioner interface {
+	Version() (uint64, error)
+}
+
+// FSSource returns a ContentSource that reads every .md file in fsys.
+// It is the default source, built from ContentFS or ContentDir.
+func FSSource(fsys fs.FS) ContentSource {
+	return fsSource{fsys: fsys}
+}
+
+type fsSource struct {
+	fsys fs.FS
+}
+
+func (s fsSource) Documents() ([]Document, error) {
+	var docs []Document
+	err := s.walk(func(name string, info fs.FileInfo) error {
+		content, err := fs.ReadFile(s.fsys, name)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, Document{Path: name, Content: content, ModTime: info.ModTime()})
+		return nil
+	})
+	return docs, err
+}
+
+func (s fsSource) Version() (uint64, error) {
+	state := newFingerprint()
+	err := s.walk(func(name string, info fs.FileInfo) error {
+		state.addFile(name, info)
+		return nil
+	})
+	return state.sum(), err
+}
+
+// walk calls fn for every markdown file in the source.
+func (s fsSource) walk(fn func(name string, info fs.FileInfo) error) error {
+	return fs.WalkDir(s.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(name, ".md") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(name, info)
+	})
+}
@@ -0,0 +1,84 @@
+package glogger
+
+import "time"
+
+// snapshot is an immutable view of the loaded blog content. Each load builds a
+// fresh snapshot off to the side and publishes it atomically, so requests see
+// either the previous posts or the new ones, never a partially loaded set.
+// Nothing in a snapshot may be modified once it has been published.
+type snapshot struct {
+	posts   []Post            // sorted newest first
+	bySlug  map[string]int    // slug -> index in posts
+	byTag   map[string][]Post // tag slug -> posts with the tag, newest first
+	tags    []TagCount        // sorted by slug
+	archive []ArchiveYear     // newest first
+	search  *searchIndex
+	pages   *pageCache // rendered pages; nil disables caching
+	modTime time.Time  // when pages listing many posts last changed; see published
+	state   uint64     // fingerprint of the content the posts were loaded from
+}
+
+func newSnapshot(posts []Post, state uint64) *snapshot {
+	s := &snapshot{
+		posts:   posts,
+		bySlug:  make(map[string]int, len(posts)),
+		byTag:   map[string][]Post{},
+		tags:    countTags(posts),
+		archive: buildArchive(posts),
+		search:  buildSearchIndex(posts),
+		state:   state,
+	}
+	for i, post := range posts {
+		if mod := post.lastModified(); mod.After(s.modTime) {
+			s.modTime = mod
+		}
+		s.bySlug[post.Slug] = i
+		for _, tag := range post.Tags {
+			slug := tagSlug(tag)
+			s.byTag[slug] = append(s.byTag[slug], post)
+		}
+	}
+	return s
+}
+
+// published sets the modification time of the pages that list many posts
+// (indexes, feeds, the sitemap, ...) as s replaces prev. Removing a post
+// changes those pages without changing any post's modification time, so
+// instead they're stamped with the time the content last changed: prev's time
+// if s was loaded from the same content, otherwise now, and always a second
+// or more after prev's so If-Modified-Since can't match a stale page.
+func (s *snapshot) published(prev *snapshot, now time.Time) {
+	if prev != nil && prev.state == s.state {
+		s.modTime = prev.modTime
+		return
+	}
+	if now.After(s.modTime) {
+		s.modTime = now
+	}
+	s.modTime = s.modTime.Truncate(time.Second)
+	if prev != nil && !s.modTime.After(prev.modTime) {
+		s.modTime = prev.modTime.Truncate(time.Second).Add(time.Second)
+	}
+}
+
+// postModTime returns the modification time of post's page. Sources that
+// don't report modification times (an embed.FS, most ContentSources) leave
+// only the frontmatter dates, which an edit needn't change, so those posts
+// fall back to when s was published.
+func (s *snapshot) postModTime(post Post) time.Time {
+	mod := post.lastModified()
+	if post.modTime.IsZero() && s.modTime.After(mod) {
+		return s.modTime
+	}
+	return mod
+}
+
+var emptySnapshot = newSnapshot([]Post{}, 0)
+
+// snapshot returns the currently published snapshot.
+func (b *Blog) snapshot() *snapshot {
+	if s := b.current.Load(); s != nil {
+		return s
+	}
+	return emptySnapshot
+}
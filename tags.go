@@ -0,0 +1,156 @@
+package glogger
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TagCount is a tag and the number of published posts that use it.
+type TagCount struct {
+	Name  string // display label, as first written in the newest post using the tag
+	Slug  string // URL-safe form used in /_tags/{slug}
+	Count int
+}
+
+// Tag index sort orders, selected with the ?sort= query parameter on /_tags/.
+const (
+	TagSortName  = "name"  // alphabetical
+	TagSortCount = "count" // most used first
+)
+
+// tagSlug returns the URL-safe form of a tag, which also identifies it: tags
+// with the same slug are the same tag. Letters are lower-cased and runs of
+// anything other than letters and digits become a single "-", so "Machine
+// Learning" becomes "machine-learning". "+" and "#" are spelled out so that
+// "C++" and "C#" don't collapse to "c".
+func tagSlug(tag string) string {
+	tag = strings.NewReplacer("+", " plus ", "#", " sharp ").Replace(tag)
+
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(tag) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// normalizeTags trims tags, applies aliases (matched case-insensitively, e.g.
+// "golang" -> "go") and drops empty tags and duplicates that differ only in
+// case or punctuation. The first spelling of each tag is kept as its label.
+func normalizeTags(tags []string, aliases map[string]string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if alias, ok := aliases[strings.ToLower(tag)]; ok {
+			tag = alias
+		}
+		slug := tagSlug(tag)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// lowerKeys returns a copy of m with its keys lower-cased.
+func lowerKeys(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return result
+}
+
+// countTags returns every tag used by posts with its post count, sorted by slug.
+func countTags(posts []Post) []TagCount {
+	index := map[string]int{}
+	var tags []TagCount
+	for _, post := range posts {
+		for _, tag := range post.Tags {
+			slug := tagSlug(tag)
+			i, ok := index[slug]
+			if !ok {
+				i = len(tags)
+				index[slug] = i
+				tags = append(tags, TagCount{Name: tag, Slug: slug})
+			}
+			tags[i].Count++
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Slug < tags[j].Slug
+	})
+	return tags
+}
+
+// findTag returns the tag with the given slug.
+func findTag(tags []TagCount, slug string) (TagCount, bool) {
+	i, ok := slices.BinarySearchFunc(tags, slug, func(t TagCount, slug string) int {
+		return strings.Compare(t.Slug, slug)
+	})
+	if !ok {
+		return TagCount{}, false
+	}
+	return tags[i], true
+}
+
+// tagLabel returns the display label for the tag with the given slug, or the
+// slug itself if no post uses the tag.
+func (s *snapshot) tagLabel(slug string) string {
+	if tag, ok := findTag(s.tags, slug); ok {
+		return tag.Name
+	}
+	return slug
+}
+
+// sortTags returns a copy of tags (which must be sorted by slug) in the given order.
+func sortTags(tags []TagCount, order string) []TagCount {
+	tags = slices.Clone(tags)
+	if order == TagSortCount {
+		sort.SliceStable(tags, func(i, j int) bool {
+			return tags[i].Count > tags[j].Count
+		})
+	}
+	return tags
+}
+
+// Tags returns every tag used by a published post with its post count,
+// sorted by slug. Useful for building a tag cloud in the host app.
+func (b *Blog) Tags() []TagCount {
+	return slices.Clone(b.snapshot().tags)
+}
+
+func (b *Blog) handleTagIndex(w http.ResponseWriter, r *http.Request) {
+	order := r.URL.Query().Get("sort")
+	if order != TagSortCount {
+		order = TagSortName
+	}
+
+	snap := b.snapshot()
+	p, err := snap.pages.render("/_tags/?sort="+order, htmlContentType, snap.modTime, func() (string, error) {
+		return b.renderer.renderTagIndex(TagIndexTemplateData{
+			Tags: sortTags(snap.tags, order),
+			Sort: order,
+		})
+	})
+	if err != nil {
+		http.Error(w, "Error rendering tag index: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	b.writePage(w, r, p, b.config.CacheControl.Pages)
+}
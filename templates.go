@@ -3,36 +3,130 @@ package glogger
 import (
 	"bytes"
 	"embed"
+	"errors"
+	"fmt"
 	"html/template"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
 )
 
-//go:embed assets/templates/*.html
+//go:embed assets/templates/*.html assets/templates/partials/*.html
 var templatesFS embed.FS
 
+var templateFuncs = template.FuncMap{
+	"tagSlug": tagSlug,
+}
+
+// readTemplate returns the template file name from overrides if it has one,
+// otherwise the built-in one.
+func readTemplate(overrides fs.FS, name string) ([]byte, error) {
+	if overrides != nil {
+		content, err := fs.ReadFile(overrides, name)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return templatesFS.ReadFile("assets/templates/" + name)
+}
+
+// partialNames returns the file names of the partials available to every
+// page: the built-in head, header and footer, plus any others in overrides.
+func partialNames(overrides fs.FS) ([]string, error) {
+	names, err := fs.Glob(templatesFS, "assets/templates/partials/*.html")
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		names[i] = strings.TrimPrefix(name, "assets/templates/")
+	}
+	if overrides != nil {
+		custom, err := fs.Glob(overrides, "partials/*.html")
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, custom...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// parseTemplate parses the page template name along with every partial. A
+// partial is included by its file name without extension, e.g.
+// {{template "header" .}} for partials/header.html.
+func parseTemplate(overrides fs.FS, name string) (*template.Template, error) {
+	partials, err := partialNames(overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := template.New(name).Funcs(templateFuncs)
+	for _, partial := range partials {
+		content, err := readTemplate(overrides, partial)
+		if err != nil {
+			return nil, err
+		}
+		partialName := strings.TrimSuffix(path.Base(partial), ".html")
+		if _, err := tmpl.New(partialName).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("parsing template %s: %w", partial, err)
+		}
+	}
+
+	content, err := readTemplate(overrides, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
 func newTemplateRenderer(config Config) (*templateRenderer, error) {
-	postTmpl, err := template.ParseFS(templatesFS, "assets/templates/post.html")
+	postTmpl, err := parseTemplate(config.TemplatesFS, "post.html")
 	if err != nil {
 		return nil, err
 	}
 
-	listTmpl, err := template.ParseFS(templatesFS, "assets/templates/list.html")
+	listTmpl, err := parseTemplate(config.TemplatesFS, "list.html")
+	if err != nil {
+		return nil, err
+	}
+
+	tagsTmpl, err := parseTemplate(config.TemplatesFS, "tags.html")
+	if err != nil {
+		return nil, err
+	}
+
+	archiveTmpl, err := parseTemplate(config.TemplatesFS, "archive.html")
 	if err != nil {
 		return nil, err
 	}
 
 	return &templateRenderer{
-		postTemplate: postTmpl,
-		listTemplate: listTmpl,
-		config:       config,
+		postTemplate:    postTmpl,
+		listTemplate:    listTmpl,
+		tagsTemplate:    tagsTmpl,
+		archiveTemplate: archiveTmpl,
+		config:          config,
 	}, nil
 }
 
 func (tr *templateRenderer) renderPost(post Post) (string, error) {
 	data := PostTemplateData{
 		Post:         post,
+		BlogTitle:    tr.config.Title,
 		BlogPrefix:   tr.config.URLPrefix,
 		ThemeCSS:     getThemePath(tr.config.URLPrefix, tr.config.Theme),
-		HighlightCSS: highlightJSStyleURL(tr.config.SyntaxTheme),
+		HighlightCSS: syntaxCSSURL(tr.config),
+		HighlightJS:  highlightJSScriptURL(tr.config),
+		FontCSS:      fontCSSURL(tr.config),
+		Offline:      tr.config.Offline,
+		EnableSearch: tr.config.EnableSearch,
 	}
 
 	var buf bytes.Buffer
@@ -43,14 +137,15 @@ func (tr *templateRenderer) renderPost(post Post) (string, error) {
 	return buf.String(), nil
 }
 
-func (tr *templateRenderer) renderPostList(posts []Post, tag string) (string, error) {
-	data := ListTemplateData{
-		Posts:      posts,
-		BlogPrefix: tr.config.URLPrefix,
-		ThemeCSS:   getThemePath(tr.config.URLPrefix, tr.config.Theme),
-		BlogTitle:  tr.config.Title,
-		Tag:        tag,
-	}
+// renderPostList renders the list template. Callers set the page-specific
+// fields of data (posts, tag, pagination); the blog-wide ones are filled in here.
+func (tr *templateRenderer) renderPostList(data ListTemplateData) (string, error) {
+	data.BlogPrefix = tr.config.URLPrefix
+	data.ThemeCSS = getThemePath(tr.config.URLPrefix, tr.config.Theme)
+	data.BlogTitle = tr.config.Title
+	data.FontCSS = fontCSSURL(tr.config)
+	data.Offline = tr.config.Offline
+	data.EnableSearch = tr.config.EnableSearch
 
 	var buf bytes.Buffer
 	if err := tr.listTemplate.Execute(&buf, data); err != nil {
@@ -59,3 +154,33 @@ func (tr *templateRenderer) renderPostList(posts []Post, tag string) (string, er
 
 	return buf.String(), nil
 }
+
+func (tr *templateRenderer) renderTagIndex(data TagIndexTemplateData) (string, error) {
+	data.BlogPrefix = tr.config.URLPrefix
+	data.ThemeCSS = getThemePath(tr.config.URLPrefix, tr.config.Theme)
+	data.BlogTitle = tr.config.Title
+	data.FontCSS = fontCSSURL(tr.config)
+	data.Offline = tr.config.Offline
+
+	var buf bytes.Buffer
+	if err := tr.tagsTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (tr *templateRenderer) renderArchive(data ArchiveTemplateData) (string, error) {
+	data.BlogPrefix = tr.config.URLPrefix
+	data.ThemeCSS = getThemePath(tr.config.URLPrefix, tr.config.Theme)
+	data.BlogTitle = tr.config.Title
+	data.FontCSS = fontCSSURL(tr.config)
+	data.Offline = tr.config.Offline
+
+	var buf bytes.Buffer
+	if err := tr.archiveTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -24,10 +24,34 @@ func getThemePath(urlPrefix, theme string) string {
 	return fmt.Sprintf("%s/_themes/%s.css", urlPrefix, theme)
 }
 
-const highlightJSBase = "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.11.1"
+const (
+	highlightJSBase = "https://cdnjs.cloudflare.com/ajax/libs/highlight.js/11.11.1"
+	googleFontsCSS  = "https://fonts.googleapis.com/css2?family=JetBrains+Mono:ital,wght@0,100..800;1,100..800&display=swap"
+)
+
+// syntaxCSSURL returns the stylesheet for config's SyntaxTheme: CSS generated
+// for server-side highlighting, or the highlight.js theme from the CDN.
+func syntaxCSSURL(config Config) string {
+	if config.ServerHighlight {
+		return fmt.Sprintf("%s/_themes/syntax/%s.css", config.URLPrefix, chromaStyle(config))
+	}
+	return fmt.Sprintf("%s/styles/%s.min.css", highlightJSBase, config.SyntaxTheme)
+}
 
-func highlightJSStyleURL(syntaxTheme string) string {
-	return fmt.Sprintf("%s/styles/%s.min.css", highlightJSBase, syntaxTheme)
+// highlightJSScriptURL returns the highlight.js script, or "" if code is
+// highlighted server-side.
+func highlightJSScriptURL(config Config) string {
+	if config.ServerHighlight {
+		return ""
+	}
+	return highlightJSBase + "/highlight.min.js"
+}
+
+func fontCSSURL(config Config) string {
+	if config.Offline {
+		return config.URLPrefix + "/_assets/fonts/dejavu-sans-mono.css"
+	}
+	return googleFontsCSS
 }
 
 func defaultSyntaxTheme(theme string) string {
@@ -0,0 +1,102 @@
+package glogger
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io/fs"
+	"time"
+)
+
+// Watch polls the blog's content every ReloadInterval and reloads the blog when a post
+// is added, modified or deleted. It blocks until ctx is cancelled.
+//
+// If a reload fails (e.g. a post has broken frontmatter) the error is written
+// to ErrorLog and the previously loaded posts continue to be served until the
+// next successful reload.
+func (b *Blog) Watch(ctx context.Context) error {
+	ticker := time.NewTicker(b.config.ReloadInterval)
+	defer ticker.Stop()
+
+	last := b.snapshot().state
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		state, err := b.contentState()
+		if err != nil {
+			b.config.ErrorLog.Printf("glogger: watching content: %v", err)
+			continue
+		}
+		if state == last {
+			continue
+		}
+		last = state
+
+		if err := b.Initialize(); err != nil {
+			b.config.ErrorLog.Printf("glogger: reload failed, serving previous posts: %v", err)
+		}
+	}
+}
+
+// contentState returns the current version of the blog's content.
+func (b *Blog) contentState() (uint64, error) {
+	version, ok, err := sourceVersion(b.config.Source)
+	if ok || err != nil {
+		return version, err
+	}
+	docs, err := b.config.Source.Documents()
+	if err != nil {
+		return 0, err
+	}
+	return documentsVersion(docs), nil
+}
+
+// sourceVersion returns the version reported by src if it is a ContentVersioner.
+func sourceVersion(src ContentSource) (version uint64, ok bool, err error) {
+	v, ok := src.(ContentVersioner)
+	if !ok {
+		return 0, false, nil
+	}
+	version, err = v.Version()
+	return version, true, err
+}
+
+// documentsVersion fingerprints docs by their full content, for sources that
+// can't report a version themselves.
+func documentsVersion(docs []Document) uint64 {
+	state := newFingerprint()
+	for _, doc := range docs {
+		state.addDocument(doc)
+	}
+	return state.sum()
+}
+
+// fingerprint hashes a set of documents or files. Adding, changing or removing
+// any of them produces a different fingerprint.
+type fingerprint struct {
+	h hash.Hash64
+}
+
+func newFingerprint() fingerprint {
+	return fingerprint{h: fnv.New64a()}
+}
+
+// addFile adds a file by path, size and modification time.
+func (s fingerprint) addFile(path string, info fs.FileInfo) {
+	fmt.Fprintf(s.h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+}
+
+// addDocument adds a document by path, modification time and content.
+func (s fingerprint) addDocument(doc Document) {
+	fmt.Fprintf(s.h, "%s\x00%d\x00%d\x00", doc.Path, len(doc.Content), doc.ModTime.UnixNano())
+	s.h.Write(doc.Content)
+}
+
+func (s fingerprint) sum() uint64 {
+	return s.h.Sum64()
+}